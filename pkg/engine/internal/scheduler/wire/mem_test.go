@@ -0,0 +1,81 @@
+package wire
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemListenerDialerRoundTrip(t *testing.T) {
+	ln := NewMemListener("mem-test")
+	defer ln.Close(context.Background())
+	dialer := NewMemDialer(ln)
+
+	acceptedCh := make(chan Conn, 1)
+	acceptErrCh := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			acceptErrCh <- err
+			return
+		}
+		acceptedCh <- conn
+	}()
+
+	client, err := dialer.Dial(context.Background(), nil, ln.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+
+	var server Conn
+	select {
+	case server = <-acceptedCh:
+	case err := <-acceptErrCh:
+		t.Fatalf("accept: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Accept")
+	}
+	defer server.Close()
+
+	want := Frame{StreamID: 7, MuxKind: muxFrameData}
+	require.NoError(t, client.Send(context.Background(), want))
+	got, err := server.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	require.Equal(t, ln.Addr(), server.LocalAddr())
+	require.Equal(t, ln.Addr(), client.RemoteAddr())
+}
+
+func TestMemListenerCloseUnblocksDial(t *testing.T) {
+	ln := NewMemListener("mem-test-close")
+	dialer := NewMemDialer(ln)
+
+	require.NoError(t, ln.Close(context.Background()))
+
+	_, err := dialer.Dial(context.Background(), nil, ln.Addr())
+	require.Error(t, err)
+}
+
+func TestMemConnCloseIsVisibleToPeer(t *testing.T) {
+	ln := NewMemListener("mem-test-peer-close")
+	defer ln.Close(context.Background())
+	dialer := NewMemDialer(ln)
+
+	acceptedCh := make(chan Conn, 1)
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		require.NoError(t, err)
+		acceptedCh <- conn
+	}()
+
+	client, err := dialer.Dial(context.Background(), nil, ln.Addr())
+	require.NoError(t, err)
+	server := <-acceptedCh
+
+	require.NoError(t, client.Close())
+
+	_, err = server.Recv(context.Background())
+	require.ErrorIs(t, err, ErrConnClosed)
+}
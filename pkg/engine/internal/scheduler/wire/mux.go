@@ -0,0 +1,412 @@
+package wire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// StreamID identifies one logical application-level stream multiplexed
+// over a single MuxConn. StreamID 0 is never handed out by OpenStream.
+// MuxConn's own control-plane frames (open, close, window update) carry
+// the ID of the stream they refer to and are told apart from data frames
+// by Frame.MuxKind.
+type StreamID uint32
+
+const controlStreamID StreamID = 0
+
+// initialStreamWindow is the number of data frames a newly opened stream
+// may send before it must wait for a window-update frame from its peer,
+// mirroring HTTP/2's initial flow-control window but counting frames
+// rather than bytes, since individual Frame sizes are already bounded by
+// the underlying codec.
+const initialStreamWindow = 32
+
+// muxFrameKind distinguishes MuxConn's own control-plane frames from
+// ordinary application data on Frame.MuxKind, so both can share a single
+// underlying Conn.
+type muxFrameKind uint8
+
+const (
+	muxFrameData muxFrameKind = iota
+	muxFrameOpenStream
+	muxFrameCloseStream
+	muxFrameWindowUpdate
+)
+
+var (
+	// ErrMuxConnClosed is returned by OpenStream, AcceptStream, and any
+	// Stream method once the underlying Conn has gone away.
+	ErrMuxConnClosed = errors.New("wire: mux connection closed")
+	// ErrStreamClosed is returned by Stream methods after the stream has
+	// been closed locally or by the peer.
+	ErrStreamClosed = errors.New("wire: stream closed")
+)
+
+// Stream is one logical, flow-controlled channel multiplexed over a
+// MuxConn. Its Send/Recv mirror Conn's, scoped to this stream's own
+// frames; Send blocks on the stream's flow-control window rather than on
+// the shared Conn, so one slow stream can't starve the others.
+type Stream interface {
+	ID() StreamID
+	Send(ctx context.Context, frame Frame) error
+	Recv(ctx context.Context) (Frame, error)
+	Close() error
+}
+
+// MuxConn multiplexes many application-level Streams over a single
+// underlying Conn, so callers that already have an established peer
+// connection can open independent request/response channels without
+// paying for a new Dial per stream. Flow control is credit-based per
+// stream, analogous to HTTP/2's WINDOW_UPDATE: a stream may send up to
+// its current window before it must wait for the peer to grant more
+// credit, giving each logical channel its own backpressure instead of
+// relying solely on the underlying Conn's single serialized write path.
+//
+// MuxConn assumes Frame carries the StreamID, MuxKind, and Credit fields
+// referenced below; callers must not call the wrapped Conn's Send/Recv
+// directly once it's been handed to NewMuxConn.
+type MuxConn struct {
+	conn Conn
+
+	mu       sync.Mutex
+	closed   bool
+	closeErr error
+	nextID   StreamID
+	streams  map[StreamID]*muxStream
+	accepted chan *muxStream
+
+	readLoopDone chan struct{}
+}
+
+// NewMuxConn wraps conn and starts the read loop that demultiplexes
+// inbound frames by StreamID. isClient partitions the StreamID space
+// exactly as HTTP/2 does between the two ends of a connection: the client
+// allocates odd IDs (1, 3, 5, ...) and the server allocates even ones (2,
+// 4, 6, ...). Without this split, a MuxConn used by both a dialer and an
+// acceptor that each call OpenStream would independently count up from the
+// same starting point and could allocate the same StreamID to two
+// different streams, silently clobbering one of them in m.streams.
+func NewMuxConn(conn Conn, isClient bool) *MuxConn {
+	first := StreamID(2)
+	if isClient {
+		first = StreamID(1)
+	}
+	m := &MuxConn{
+		conn:         conn,
+		nextID:       first,
+		streams:      make(map[StreamID]*muxStream),
+		accepted:     make(chan *muxStream, 16),
+		readLoopDone: make(chan struct{}),
+	}
+	go m.readLoop()
+	return m
+}
+
+// OpenStream allocates a new StreamID, tells the peer a stream has been
+// opened, and returns a Stream the caller can start writing to
+// immediately; writes block on the initial flow-control window rather
+// than on any acknowledgement from the peer.
+func (m *MuxConn) OpenStream(ctx context.Context) (Stream, error) {
+	m.mu.Lock()
+	if m.closed {
+		err := m.closeErr
+		m.mu.Unlock()
+		return nil, err
+	}
+	id := m.nextID
+	m.nextID += 2
+	s := newMuxStream(m, id, initialStreamWindow)
+	m.streams[id] = s
+	m.mu.Unlock()
+
+	if err := m.conn.Send(ctx, Frame{StreamID: id, MuxKind: muxFrameOpenStream}); err != nil {
+		m.mu.Lock()
+		delete(m.streams, id)
+		m.mu.Unlock()
+		return nil, fmt.Errorf("wire: open stream: %w", err)
+	}
+	return s, nil
+}
+
+// AcceptStream blocks until the peer opens a new stream, ctx is
+// canceled, or the MuxConn is closed.
+func (m *MuxConn) AcceptStream(ctx context.Context) (Stream, error) {
+	select {
+	case s, ok := <-m.accepted:
+		if !ok {
+			return nil, m.closeErr
+		}
+		return s, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close tears down every open stream and the underlying Conn.
+func (m *MuxConn) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	m.closeErr = ErrMuxConnClosed
+	streams := make([]*muxStream, 0, len(m.streams))
+	for _, s := range m.streams {
+		streams = append(streams, s)
+	}
+	m.streams = nil
+	close(m.accepted)
+	m.mu.Unlock()
+
+	for _, s := range streams {
+		s.closeLocal(ErrMuxConnClosed)
+	}
+	return m.conn.Close()
+}
+
+// readLoop demultiplexes frames off the underlying Conn by StreamID,
+// dispatching data frames to the matching muxStream's inbox and handling
+// control-plane frames itself.
+func (m *MuxConn) readLoop() {
+	defer close(m.readLoopDone)
+	ctx := context.Background()
+	for {
+		frame, err := m.conn.Recv(ctx)
+		if err != nil {
+			m.shutdown(err)
+			return
+		}
+
+		switch frame.MuxKind {
+		case muxFrameOpenStream:
+			m.handleOpenStream(frame.StreamID)
+		case muxFrameCloseStream:
+			m.handleCloseStream(frame.StreamID)
+		case muxFrameWindowUpdate:
+			m.handleWindowUpdate(frame.StreamID, frame.Credit)
+		default:
+			m.handleData(frame)
+		}
+	}
+}
+
+func (m *MuxConn) handleOpenStream(id StreamID) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	s := newMuxStream(m, id, initialStreamWindow)
+	m.streams[id] = s
+	m.mu.Unlock()
+
+	select {
+	case m.accepted <- s:
+	default:
+		// The backlog of unaccepted streams is full; refuse rather than
+		// block the shared read loop indefinitely on a slow acceptor.
+		m.mu.Lock()
+		delete(m.streams, id)
+		m.mu.Unlock()
+		_ = m.conn.Send(context.Background(), Frame{StreamID: id, MuxKind: muxFrameCloseStream})
+	}
+}
+
+func (m *MuxConn) handleCloseStream(id StreamID) {
+	m.mu.Lock()
+	s, ok := m.streams[id]
+	if ok {
+		delete(m.streams, id)
+	}
+	m.mu.Unlock()
+	if ok {
+		s.closeLocal(ErrStreamClosed)
+	}
+}
+
+func (m *MuxConn) handleWindowUpdate(id StreamID, credit uint32) {
+	m.mu.Lock()
+	s, ok := m.streams[id]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.grantSendCredit(int(credit))
+}
+
+func (m *MuxConn) handleData(frame Frame) {
+	m.mu.Lock()
+	s, ok := m.streams[frame.StreamID]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case s.inbox <- frame:
+	default:
+		// The peer exceeded the window it was granted; drop the frame
+		// rather than block the shared read loop on one bad stream.
+	}
+}
+
+func (m *MuxConn) shutdown(err error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.closeErr = err
+	streams := make([]*muxStream, 0, len(m.streams))
+	for _, s := range m.streams {
+		streams = append(streams, s)
+	}
+	m.streams = nil
+	close(m.accepted)
+	m.mu.Unlock()
+
+	for _, s := range streams {
+		s.closeLocal(err)
+	}
+}
+
+// muxStream is MuxConn's Stream implementation. Send credit is tracked
+// as a plain counter guarded by sendMu/sendCond rather than a buffered
+// channel, since windowUpdate frames can grant credit in arbitrary
+// increments that a fixed-capacity channel can't always absorb.
+type muxStream struct {
+	id     StreamID
+	parent *MuxConn
+
+	inbox     chan Frame
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+
+	sendMu     sync.Mutex
+	sendCond   *sync.Cond
+	sendWindow int
+
+	recvMu       sync.Mutex
+	recvConsumed int
+}
+
+func newMuxStream(parent *MuxConn, id StreamID, initialWindow int) *muxStream {
+	s := &muxStream{
+		id:         id,
+		parent:     parent,
+		inbox:      make(chan Frame, initialWindow),
+		closeCh:    make(chan struct{}),
+		sendWindow: initialWindow,
+	}
+	s.sendCond = sync.NewCond(&s.sendMu)
+	return s
+}
+
+func (s *muxStream) ID() StreamID { return s.id }
+
+// Send blocks until the stream has flow-control credit for another data
+// frame, then writes frame to the underlying Conn tagged with this
+// stream's StreamID.
+func (s *muxStream) Send(ctx context.Context, frame Frame) error {
+	if err := s.acquireSendCredit(ctx); err != nil {
+		return err
+	}
+	frame.StreamID = s.id
+	frame.MuxKind = muxFrameData
+	return s.parent.conn.Send(ctx, frame)
+}
+
+func (s *muxStream) acquireSendCredit(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	// sync.Cond has no built-in context support, so a watcher goroutine
+	// broadcasts to wake this call's Wait if ctx is canceled first.
+	if ctx.Done() != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.sendCond.Broadcast()
+			case <-stop:
+			}
+		}()
+	}
+
+	for s.sendWindow <= 0 {
+		if s.closeErr != nil {
+			return s.closeErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.sendCond.Wait()
+	}
+	if s.closeErr != nil {
+		return s.closeErr
+	}
+	s.sendWindow--
+	return nil
+}
+
+func (s *muxStream) grantSendCredit(credit int) {
+	s.sendMu.Lock()
+	s.sendWindow += credit
+	s.sendMu.Unlock()
+	s.sendCond.Broadcast()
+}
+
+// Recv returns the next data frame sent by the peer on this stream,
+// granting flow-control credit back once enough frames have been
+// consumed to make another window-update frame worthwhile.
+func (s *muxStream) Recv(ctx context.Context) (Frame, error) {
+	select {
+	case frame, ok := <-s.inbox:
+		if !ok {
+			return Frame{}, s.closeErr
+		}
+		s.grantCreditIfNeeded(ctx)
+		return frame, nil
+	case <-s.closeCh:
+		return Frame{}, s.closeErr
+	case <-ctx.Done():
+		return Frame{}, ctx.Err()
+	}
+}
+
+func (s *muxStream) grantCreditIfNeeded(ctx context.Context) {
+	s.recvMu.Lock()
+	s.recvConsumed++
+	grant := 0
+	if s.recvConsumed >= initialStreamWindow/2 {
+		grant = s.recvConsumed
+		s.recvConsumed = 0
+	}
+	s.recvMu.Unlock()
+	if grant == 0 {
+		return
+	}
+	_ = s.parent.conn.Send(ctx, Frame{StreamID: s.id, MuxKind: muxFrameWindowUpdate, Credit: uint32(grant)})
+}
+
+// Close signals the peer that this stream is done and releases any
+// callers blocked in Send or Recv.
+func (s *muxStream) Close() error {
+	s.closeLocal(ErrStreamClosed)
+	return s.parent.conn.Send(context.Background(), Frame{StreamID: s.id, MuxKind: muxFrameCloseStream})
+}
+
+func (s *muxStream) closeLocal(err error) {
+	s.closeOnce.Do(func() {
+		s.sendMu.Lock()
+		s.closeErr = err
+		s.sendMu.Unlock()
+		close(s.closeCh)
+		s.sendCond.Broadcast()
+	})
+}
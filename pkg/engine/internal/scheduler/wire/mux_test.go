@@ -0,0 +1,157 @@
+package wire
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newMuxConnPair wires two MuxConns together over an in-memory Conn pair,
+// one on each side of the client/server StreamID partition.
+func newMuxConnPair(t *testing.T) (client, server *MuxConn) {
+	t.Helper()
+
+	ln := NewMemListener("mux-test")
+	dialer := NewMemDialer(ln)
+
+	acceptedCh := make(chan Conn, 1)
+	acceptErrCh := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			acceptErrCh <- err
+			return
+		}
+		acceptedCh <- conn
+	}()
+
+	clientConn, err := dialer.Dial(context.Background(), nil, ln.Addr())
+	require.NoError(t, err)
+
+	var serverConn Conn
+	select {
+	case serverConn = <-acceptedCh:
+	case err := <-acceptErrCh:
+		t.Fatalf("accept: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Accept")
+	}
+
+	client = NewMuxConn(clientConn, true)
+	server = NewMuxConn(serverConn, false)
+	return client, server
+}
+
+func TestMuxConnOpenStreamPartitionsIDsByRole(t *testing.T) {
+	client, server := newMuxConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	ctx := context.Background()
+
+	cs1, err := client.OpenStream(ctx)
+	require.NoError(t, err)
+	cs2, err := client.OpenStream(ctx)
+	require.NoError(t, err)
+
+	ss1, err := server.OpenStream(ctx)
+	require.NoError(t, err)
+	ss2, err := server.OpenStream(ctx)
+	require.NoError(t, err)
+
+	// The client allocates odd IDs, the server even ones, so the two sides
+	// can both call OpenStream without ever colliding on the same ID.
+	require.Equal(t, StreamID(1), cs1.ID())
+	require.Equal(t, StreamID(3), cs2.ID())
+	require.Equal(t, StreamID(2), ss1.ID())
+	require.Equal(t, StreamID(4), ss2.ID())
+}
+
+func TestMuxConnOpenStreamFromBothSidesNeverCollides(t *testing.T) {
+	client, server := newMuxConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	ctx := context.Background()
+	seen := make(map[StreamID]struct{})
+	var mu sync.Mutex
+
+	const n = 20
+	errCh := make(chan error, 2*n)
+	record := func(s Stream) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, dup := seen[s.ID()]; dup {
+			return fmt.Errorf("duplicate StreamID %d allocated by both sides", s.ID())
+		}
+		seen[s.ID()] = struct{}{}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		go func() {
+			s, err := client.OpenStream(ctx)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			errCh <- record(s)
+		}()
+		go func() {
+			s, err := server.OpenStream(ctx)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			errCh <- record(s)
+		}()
+	}
+	for i := 0; i < 2*n; i++ {
+		require.NoError(t, <-errCh)
+	}
+	require.Len(t, seen, 2*n)
+}
+
+func TestMuxConnAcceptStreamReceivesPeerOpenedStream(t *testing.T) {
+	client, server := newMuxConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	ctx := context.Background()
+	cs, err := client.OpenStream(ctx)
+	require.NoError(t, err)
+
+	ss, err := server.AcceptStream(ctx)
+	require.NoError(t, err)
+	require.Equal(t, cs.ID(), ss.ID())
+}
+
+func TestMuxConnStreamSendRecvRoundTrip(t *testing.T) {
+	client, server := newMuxConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	ctx := context.Background()
+	cs, err := client.OpenStream(ctx)
+	require.NoError(t, err)
+	ss, err := server.AcceptStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, cs.Send(ctx, Frame{StreamID: cs.ID()}))
+	got, err := ss.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, cs.ID(), got.StreamID)
+}
+
+func TestMuxConnCloseUnblocksOpenStream(t *testing.T) {
+	client, server := newMuxConnPair(t)
+	defer server.Close()
+
+	require.NoError(t, client.Close())
+
+	_, err := client.OpenStream(context.Background())
+	require.ErrorIs(t, err, ErrMuxConnClosed)
+}
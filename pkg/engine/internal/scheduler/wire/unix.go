@@ -0,0 +1,223 @@
+package wire
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// UnixListener is a Listener that speaks the same frameCodec framing as
+// HTTP2Listener, but directly over an AF_UNIX socket. It's meant for
+// co-located sidecar deployments where HTTP/2's per-request overhead and
+// the Loki-Peer-Address header dance are unnecessary because both ends
+// are already identified by the socket path they share.
+type UnixListener struct {
+	path string
+	ln   *net.UnixListener
+
+	codec     frameCodec
+	incoming  chan Conn
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// UnixListenerOpt configures a UnixListener.
+type UnixListenerOpt func(*unixListenerOpts)
+
+type unixListenerOpts struct {
+	codec frameCodec
+}
+
+// WithUnixListenerFrameCodec overrides the frameCodec used to encode and
+// decode Frames on accepted connections; it defaults to
+// defaultFrameCodec.
+func WithUnixListenerFrameCodec(codec frameCodec) UnixListenerOpt {
+	return func(o *unixListenerOpts) { o.codec = codec }
+}
+
+// NewUnixListener binds an AF_UNIX socket at path, removing any stale
+// socket file left behind by a previous, uncleanly terminated process.
+func NewUnixListener(path string, opts ...UnixListenerOpt) (*UnixListener, error) {
+	o := unixListenerOpts{codec: defaultFrameCodec}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("wire: remove stale unix socket %q: %w", path, err)
+	}
+
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("wire: listen on unix socket %q: %w", path, err)
+	}
+
+	l := &UnixListener{
+		path:     path,
+		ln:       ln,
+		codec:    o.codec,
+		incoming: make(chan Conn),
+		closed:   make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l, nil
+}
+
+func (l *UnixListener) acceptLoop() {
+	for {
+		c, err := l.ln.AcceptUnix()
+		if err != nil {
+			return
+		}
+		select {
+		case l.incoming <- newUnixConn(c, l.codec):
+		case <-l.closed:
+			_ = c.Close()
+			return
+		}
+	}
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *UnixListener) Accept(ctx context.Context) (Conn, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case conn := <-l.incoming:
+		return conn, nil
+	}
+}
+
+// Close closes the listener and removes the socket file.
+func (l *UnixListener) Close(_ context.Context) error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	err := l.ln.Close()
+	_ = os.Remove(l.path)
+	return err
+}
+
+// Addr returns the listener's unix socket address.
+func (l *UnixListener) Addr() net.Addr {
+	return l.ln.Addr()
+}
+
+// UnixDialer dials a UnixListener over AF_UNIX, using the same
+// frameCodec framing as HTTP2Dialer but without HTTP/2's per-stream
+// overhead or the Loki-Peer-Address header, since a unix socket dialer
+// only ever has one peer: the path it was constructed with.
+type UnixDialer struct {
+	path  string
+	codec frameCodec
+}
+
+// UnixDialerOpt configures a UnixDialer.
+type UnixDialerOpt func(*unixDialerOpts)
+
+type unixDialerOpts struct {
+	codec frameCodec
+}
+
+// WithUnixDialerFrameCodec overrides the frameCodec used to encode and
+// decode Frames; it defaults to defaultFrameCodec.
+func WithUnixDialerFrameCodec(codec frameCodec) UnixDialerOpt {
+	return func(o *unixDialerOpts) { o.codec = codec }
+}
+
+// NewUnixDialer returns a Dialer that connects to the AF_UNIX socket at
+// path.
+func NewUnixDialer(path string, opts ...UnixDialerOpt) *UnixDialer {
+	o := unixDialerOpts{codec: defaultFrameCodec}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &UnixDialer{path: path, codec: o.codec}
+}
+
+// Dial connects to the dialer's configured unix socket path. from/to are
+// accepted to satisfy Dialer but otherwise unused, since a unix socket
+// dialer has exactly one peer.
+func (d *UnixDialer) Dial(ctx context.Context, _, _ net.Addr) (Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", d.path)
+	if err != nil {
+		return nil, fmt.Errorf("wire: dial unix socket %q: %w", d.path, err)
+	}
+	return newUnixConn(conn.(*net.UnixConn), d.codec), nil
+}
+
+// unixConn implements Conn directly over a *net.UnixConn, encoding and
+// decoding Frames with codec rather than wrapping them in HTTP/2
+// requests and responses the way http2Conn does.
+type unixConn struct {
+	conn  *net.UnixConn
+	codec frameCodec
+
+	writeMu sync.Mutex
+}
+
+func newUnixConn(conn *net.UnixConn, codec frameCodec) *unixConn {
+	return &unixConn{conn: conn, codec: codec}
+}
+
+// withCancel arranges for a pending read or write on c.conn to be
+// interrupted if ctx is canceled before it completes, since net.Conn has
+// no context-aware I/O of its own. Callers must invoke the returned
+// func once their I/O call returns.
+func (c *unixConn) withCancel(ctx context.Context) func() {
+	if dl, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(dl)
+	}
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+	return func() { close(stop) }
+}
+
+func (c *unixConn) Send(ctx context.Context, frame Frame) error {
+	done := c.withCancel(ctx)
+	defer done()
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.codec.EncodeTo(c.conn, frame); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *unixConn) Recv(ctx context.Context) (Frame, error) {
+	done := c.withCancel(ctx)
+	defer done()
+
+	frame, err := c.codec.DecodeFrom(c.conn)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Frame{}, ctx.Err()
+		}
+		return Frame{}, err
+	}
+	return frame, nil
+}
+
+func (c *unixConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *unixConn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *unixConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
@@ -0,0 +1,106 @@
+package wire
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixListenerDialerRoundTrip(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "wire-test.sock")
+
+	ln, err := NewUnixListener(sockPath)
+	require.NoError(t, err)
+	defer ln.Close(context.Background())
+
+	dialer := NewUnixDialer(sockPath)
+
+	acceptedCh := make(chan Conn, 1)
+	acceptErrCh := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			acceptErrCh <- err
+			return
+		}
+		acceptedCh <- conn
+	}()
+
+	client, err := dialer.Dial(context.Background(), nil, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var server Conn
+	select {
+	case server = <-acceptedCh:
+	case err := <-acceptErrCh:
+		t.Fatalf("accept: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Accept")
+	}
+	defer server.Close()
+
+	want := Frame{StreamID: 3, MuxKind: muxFrameData}
+	require.NoError(t, client.Send(context.Background(), want))
+	got, err := server.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestNewUnixListenerRemovesStaleSocketFile(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "wire-stale.sock")
+
+	first, err := NewUnixListener(sockPath)
+	require.NoError(t, err)
+
+	// Simulate an unclean shutdown: the listener's socket file is left on
+	// disk without anything listening on it anymore.
+	first.ln.SetUnlinkOnClose(false)
+	require.NoError(t, first.ln.Close())
+
+	second, err := NewUnixListener(sockPath)
+	require.NoError(t, err)
+	defer second.Close(context.Background())
+}
+
+func TestUnixListenerCloseRemovesSocketFile(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "wire-remove.sock")
+
+	ln, err := NewUnixListener(sockPath)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close(context.Background()))
+
+	_, err = os.Stat(sockPath)
+	require.True(t, os.IsNotExist(err), "socket file still exists after Close")
+
+	_, err = NewUnixDialer(sockPath).Dial(context.Background(), nil, nil)
+	require.Error(t, err)
+}
+
+func TestUnixConnCloseIsVisibleToPeer(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "wire-peer-close.sock")
+
+	ln, err := NewUnixListener(sockPath)
+	require.NoError(t, err)
+	defer ln.Close(context.Background())
+
+	acceptedCh := make(chan Conn, 1)
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		require.NoError(t, err)
+		acceptedCh <- conn
+	}()
+
+	client, err := NewUnixDialer(sockPath).Dial(context.Background(), nil, nil)
+	require.NoError(t, err)
+	server := <-acceptedCh
+
+	require.NoError(t, client.Close())
+
+	_, err = server.Recv(context.Background())
+	require.Error(t, err)
+}
@@ -8,7 +8,9 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/log"
@@ -20,6 +22,17 @@ import (
 // to a client.
 const peerAddressHeader = "Loki-Peer-Address"
 
+// defaultKeepaliveTimeout is applied by NewHTTP2Dialer when
+// WithKeepaliveInterval is set without a matching WithKeepaliveTimeout, so a
+// keepalive ping isn't given an already-expired context.WithTimeout
+// deadline (timeout 0 means "expired immediately").
+const defaultKeepaliveTimeout = 10 * time.Second
+
+// ErrPeerUnresponsive is returned by a Conn's Send/Recv once its keepalive
+// ping has timed out, distinguishing a peer that stopped responding from a
+// conn that was closed gracefully (ErrConnClosed).
+var ErrPeerUnresponsive = errors.New("wire: peer unresponsive")
+
 // HTTP2Listener implements Listener for HTTP/2-based connections.
 type HTTP2Listener struct {
 	logger log.Logger
@@ -28,7 +41,15 @@ type HTTP2Listener struct {
 	incoming  chan *http2Conn
 	closeOnce sync.Once
 	closed    chan struct{}
-	codec     *protobufCodec
+	codec     frameCodec
+	tlsConfig *tls.Config
+
+	keepaliveInterval time.Duration
+	keepaliveTimeout  time.Duration
+
+	// defaultCompression is applied to accepted connections whose request
+	// doesn't carry a Loki-Wire-Compression header.
+	defaultCompression CompressionType
 }
 
 var (
@@ -39,6 +60,16 @@ var (
 type http2ListenerOpts struct {
 	// Logger is used for logging.
 	Logger log.Logger
+
+	// TLSConfig, if set, is served back to callers via HTTP2Listener.TLSConfig
+	// so they can terminate TLS (and, with ClientAuth/ClientCAs set, mTLS) on
+	// the *http.Server fronting this listener.
+	TLSConfig *tls.Config
+
+	KeepaliveInterval time.Duration
+	KeepaliveTimeout  time.Duration
+
+	DefaultCompression CompressionType
 }
 
 type HTTP2ListenerOptFunc func(*http2ListenerOpts)
@@ -49,25 +80,95 @@ func WithHTTP2ListenerLogger(logger log.Logger) HTTP2ListenerOptFunc {
 	}
 }
 
+// WithHTTP2ListenerTLSConfig configures the listener to report cfg (with
+// "h2" added to NextProtos) from TLSConfig, for use as the TLSConfig of the
+// *http.Server that serves this listener's handler. Setting cfg.ClientAuth
+// to tls.RequireAndVerifyClientCert enables mTLS; the verified peer
+// certificate is then available via http2Conn.RemotePeerIdentity on
+// accepted connections.
+func WithHTTP2ListenerTLSConfig(cfg *tls.Config) HTTP2ListenerOptFunc {
+	return func(o *http2ListenerOpts) {
+		o.TLSConfig = cfg
+	}
+}
+
+// WithHTTP2ListenerKeepalive configures the HTTP/2 PING-based keepalive
+// reported back via HTTP2ServerConfig: interval is how often the peer
+// connection is pinged, and timeout is how long a ping may go unanswered
+// before the server considers the peer unresponsive and tears the
+// connection down (golang.org/x/net/http2.Server's ReadIdleTimeout and
+// PingTimeout respectively). HTTP2Listener itself is just an http.Handler,
+// so it can't send these pings directly; the caller's http2.Server does.
+func WithHTTP2ListenerKeepalive(interval, timeout time.Duration) HTTP2ListenerOptFunc {
+	return func(o *http2ListenerOpts) {
+		o.KeepaliveInterval = interval
+		o.KeepaliveTimeout = timeout
+	}
+}
+
+// WithHTTP2ListenerFrameCompression sets the compression scheme applied to
+// an accepted connection when its request doesn't carry a
+// Loki-Wire-Compression header. Connections that do carry the header use
+// whatever scheme it names instead, so a dialer can always negotiate a
+// different (or no) compression on a per-connection basis.
+func WithHTTP2ListenerFrameCompression(compression CompressionType) HTTP2ListenerOptFunc {
+	return func(o *http2ListenerOpts) {
+		o.DefaultCompression = compression
+	}
+}
+
 // NewHTTP2Listener creates a new HTTP/2 listener on the specified address.
-func NewHTTP2Listener(addr net.Addr, optFuncs ...HTTP2ListenerOptFunc) *HTTP2Listener {
+// It returns an error if configured with a CompressionType that isn't
+// actually implemented in this build (see isSupportedCompression).
+func NewHTTP2Listener(addr net.Addr, optFuncs ...HTTP2ListenerOptFunc) (*HTTP2Listener, error) {
 	opts := http2ListenerOpts{
 		Logger: log.NewNopLogger(),
 	}
 	for _, optFunc := range optFuncs {
 		optFunc(&opts)
 	}
+	if !isSupportedCompression(opts.DefaultCompression) {
+		return nil, fmt.Errorf("wire: unsupported compression scheme %s", opts.DefaultCompression)
+	}
 
 	l := &HTTP2Listener{
 		addr:   addr,
 		logger: opts.Logger,
 
-		incoming: make(chan *http2Conn),
-		closed:   make(chan struct{}),
-		codec:    defaultFrameCodec,
+		incoming:           make(chan *http2Conn),
+		closed:             make(chan struct{}),
+		codec:              defaultFrameCodec,
+		keepaliveInterval:  opts.KeepaliveInterval,
+		keepaliveTimeout:   opts.KeepaliveTimeout,
+		defaultCompression: opts.DefaultCompression,
+	}
+
+	if opts.TLSConfig != nil {
+		l.tlsConfig = withALPN(opts.TLSConfig)
 	}
 
-	return l
+	return l, nil
+}
+
+// TLSConfig returns the TLS configuration to serve this listener's handler
+// with, for use as an *http.Server's TLSConfig, or nil if the listener was
+// created without WithHTTP2ListenerTLSConfig. HTTP2Listener only implements
+// http.Handler; it doesn't create the net.Listener or *http.Server itself,
+// so TLS termination remains the caller's responsibility.
+func (l *HTTP2Listener) TLSConfig() *tls.Config {
+	return l.tlsConfig
+}
+
+// HTTP2ServerConfig returns an *http2.Server configured with the keepalive
+// settings from WithHTTP2ListenerKeepalive, for callers to pass to
+// http2.ConfigureServer (or as *http.Server.HTTP2) when setting up the
+// server that serves this listener's handler. Returns a zero-value
+// *http2.Server if no keepalive was configured.
+func (l *HTTP2Listener) HTTP2ServerConfig() *http2.Server {
+	return &http2.Server{
+		ReadIdleTimeout: l.keepaliveInterval,
+		PingTimeout:     l.keepaliveTimeout,
+	}
 }
 
 // ServeHTTP handles incoming connections.
@@ -107,7 +208,14 @@ func (l *HTTP2Listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	conn := newHTTP2Conn(r.Context(), l.Addr(), remoteAddr, r.Body, w, rc, l.codec)
+	compression := l.defaultCompression
+	if h := r.Header.Get(compressionHeader); h != "" {
+		compression = parseCompressionType(h)
+	}
+
+	conn := newHTTP2Conn(r.Context(), l.Addr(), remoteAddr, r.Body, w, rc, newFrameCodec(l.codec, compression))
+	conn.peerIdentity = peerIdentityFromTLS(r.TLS)
+	conn.compression = compression
 	defer conn.Close()
 
 	// Wait until connection is accepted by HTTP2Listener.Accept(ctx)
@@ -127,7 +235,8 @@ func (l *HTTP2Listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case l.incoming <- conn:
-		// connection accepted
+		// connection accepted; echo back the compression actually applied
+		w.Header().Set(compressionHeader, compression.String())
 		w.WriteHeader(http.StatusOK)
 		err := conn.responseController.Flush()
 		if err != nil {
@@ -170,7 +279,7 @@ type http2Conn struct {
 	localAddr  net.Addr
 	remoteAddr net.Addr
 
-	codec              *protobufCodec
+	codec              frameCodec
 	reader             io.ReadCloser
 	writer             io.Writer
 	responseController *http.ResponseController
@@ -179,8 +288,61 @@ type http2Conn struct {
 	writeMu   sync.Mutex
 	closeOnce sync.Once
 	closed    chan struct{}
+	closeErr  error
 
 	incomingCh chan incomingFrame
+
+	// peerIdentity is the verified identity of the remote peer's TLS client
+	// certificate, set when mTLS is in use. It is nil otherwise.
+	peerIdentity *PeerIdentity
+
+	pingMu   sync.Mutex
+	lastPing time.Time
+
+	// compression is the scheme negotiated for this connection, reported via
+	// CompressionInfo.
+	compression CompressionType
+}
+
+// CompressionInfo returns the frame compression scheme negotiated for this
+// connection.
+func (c *http2Conn) CompressionInfo() CompressionType {
+	return c.compression
+}
+
+// PeerIdentity describes the verified identity of a peer, extracted from
+// its TLS client certificate.
+type PeerIdentity struct {
+	CommonName string
+	DNSNames   []string
+}
+
+// peerIdentityFromTLS builds a PeerIdentity from the leaf certificate of an
+// established TLS connection, or returns nil if state is nil or carries no
+// client certificate (e.g. TLS wasn't used, or ClientAuth didn't require
+// one).
+func peerIdentityFromTLS(state *tls.ConnectionState) *PeerIdentity {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := state.PeerCertificates[0]
+	return &PeerIdentity{
+		CommonName: leaf.Subject.CommonName,
+		DNSNames:   leaf.DNSNames,
+	}
+}
+
+// withALPN returns a shallow copy of cfg with "h2" added to NextProtos if
+// it isn't already present, so HTTP/2 is correctly negotiated over TLS.
+func withALPN(cfg *tls.Config) *tls.Config {
+	out := cfg.Clone()
+	for _, proto := range out.NextProtos {
+		if proto == "h2" {
+			return out
+		}
+	}
+	out.NextProtos = append(out.NextProtos, "h2")
+	return out
 }
 
 type incomingFrame struct {
@@ -198,7 +360,7 @@ func newHTTP2Conn(
 	reader io.ReadCloser,
 	writer io.Writer,
 	responseController *http.ResponseController,
-	codec *protobufCodec,
+	codec frameCodec,
 ) *http2Conn {
 	c := &http2Conn{
 		ctx:                ctx,
@@ -217,6 +379,12 @@ func newHTTP2Conn(
 func (c *http2Conn) readLoop(ctx context.Context) {
 	for {
 		frame, err := c.codec.DecodeFrom(c.reader)
+		if err == nil {
+			// Any successfully decoded frame is evidence the peer is alive;
+			// this is a passive proxy for the lack of a lower-level PING hook
+			// on the server side of an http.Handler-backed connection.
+			c.recordPing(time.Now())
+		}
 		incoming := incomingFrame{frame: frame, err: err}
 		select {
 		case <-ctx.Done():
@@ -239,7 +407,7 @@ func (c *http2Conn) Send(ctx context.Context, frame Frame) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-c.closed:
-		return ErrConnClosed
+		return c.closedErr()
 	default:
 	}
 
@@ -271,19 +439,35 @@ func (c *http2Conn) Recv(ctx context.Context) (Frame, error) {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-c.closed:
-		return nil, ErrConnClosed
+		return nil, c.closedErr()
 	case f := <-c.incomingCh:
 		return f.frame, f.err
 	}
 }
 
-// Close closes the connection.
+// Close closes the connection gracefully.
 func (c *http2Conn) Close() error {
+	return c.closeWithErr(ErrConnClosed)
+}
+
+// markUnresponsive closes the connection because its keepalive ping timed
+// out, so subsequent Send/Recv calls return ErrPeerUnresponsive instead of
+// the graceful ErrConnClosed.
+func (c *http2Conn) markUnresponsive() {
+	_ = c.closeWithErr(ErrPeerUnresponsive)
+}
+
+// closeWithErr closes the connection, recording cause as the error that
+// Send/Recv report once closed.closeErr is only ever written here, before
+// closed is closed, so reading it after observing <-c.closed requires no
+// extra synchronization.
+func (c *http2Conn) closeWithErr(cause error) error {
 	c.writeMu.Lock()
 	defer c.writeMu.Unlock()
 
 	var err error
 	c.closeOnce.Do(func() {
+		c.closeErr = cause
 		close(c.closed)
 		err = c.reader.Close()
 		if c.cleanup != nil {
@@ -293,6 +477,35 @@ func (c *http2Conn) Close() error {
 	return err
 }
 
+// closedErr returns the error to report from Send/Recv once the connection
+// has closed: the cause it was closed with, or ErrConnClosed as a fallback.
+func (c *http2Conn) closedErr() error {
+	if c.closeErr != nil {
+		return c.closeErr
+	}
+	return ErrConnClosed
+}
+
+// recordPing records t as the time of the most recent liveness signal for
+// this connection (a successful keepalive ping, or a received frame on the
+// server side), for use by LastPing.
+func (c *http2Conn) recordPing(t time.Time) {
+	c.pingMu.Lock()
+	defer c.pingMu.Unlock()
+	if t.After(c.lastPing) {
+		c.lastPing = t
+	}
+}
+
+// LastPing returns the time of the most recent liveness signal observed for
+// this connection, for use in health metrics. It is the zero time if no
+// ping has succeeded yet.
+func (c *http2Conn) LastPing() time.Time {
+	c.pingMu.Lock()
+	defer c.pingMu.Unlock()
+	return c.lastPing
+}
+
 // LocalAddr returns the local network address.
 func (c *http2Conn) LocalAddr() net.Addr {
 	return c.localAddr
@@ -303,48 +516,271 @@ func (c *http2Conn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
 
-// HTTP2Dialer holds an http client to pool the connections.
+// RemotePeerIdentity returns the verified identity of the remote peer, from
+// its TLS client certificate, or nil if mTLS wasn't used for this
+// connection. Callers that need to authenticate peers (e.g. the ring/gossip
+// layer) should use this instead of trusting RemoteAddr or the
+// Loki-Peer-Address header blindly.
+func (c *http2Conn) RemotePeerIdentity() *PeerIdentity {
+	return c.peerIdentity
+}
+
+// HTTP2Dialer keeps a small pool of long-lived *http2.ClientConn per remote
+// peer, so repeated Dial calls to the same address reuse an existing
+// TCP/TLS session and HTTP/2 connection instead of paying for a fresh
+// handshake every time. Each logical wire.Conn returned by Dial is a
+// separate HTTP/2 stream multiplexed over one of the pooled connections.
 type HTTP2Dialer struct {
-	client *http.Client
-	codec  *protobufCodec
-	path   string
+	codec     frameCodec
+	path      string
+	tlsConfig *tls.Config // nil => cleartext h2c
+	transport *http2.Transport
+
+	maxConnsPerPeer   int
+	maxStreamsPerConn uint32
+	idleConnTimeout   time.Duration
+
+	keepaliveInterval   time.Duration
+	keepaliveTimeout    time.Duration
+	permitWithoutStream bool
+
+	// compression is the scheme this dialer proposes via the
+	// Loki-Wire-Compression header; the listener's echoed value (which may
+	// differ if it doesn't recognize the scheme) is what's actually used.
+	compression CompressionType
+
+	mu    sync.Mutex
+	peers map[string]*peerPool
 }
 
 var _ Dialer = (*HTTP2Dialer)(nil)
 
+// peerPool is the set of pooled *http2.ClientConn open to one peer address.
+type peerPool struct {
+	mu    sync.Mutex
+	conns []*pooledConn
+}
+
+// pooledConn tracks one pooled *http2.ClientConn along with enough
+// bookkeeping to enforce per-conn stream limits and idle eviction.
+type pooledConn struct {
+	cc       *http2.ClientConn
+	lastUsed time.Time
+	active   int32
+
+	streamsMu sync.Mutex
+	streams   map[*http2Conn]struct{}
+}
+
+// addStream registers hc as a live stream multiplexed onto pc, so a
+// keepalive ping failure can mark it unresponsive.
+func (pc *pooledConn) addStream(hc *http2Conn) {
+	pc.streamsMu.Lock()
+	defer pc.streamsMu.Unlock()
+	if pc.streams == nil {
+		pc.streams = make(map[*http2Conn]struct{})
+	}
+	pc.streams[hc] = struct{}{}
+}
+
+// removeStream unregisters hc, called from its cleanup once closed.
+func (pc *pooledConn) removeStream(hc *http2Conn) {
+	pc.streamsMu.Lock()
+	defer pc.streamsMu.Unlock()
+	delete(pc.streams, hc)
+}
+
+// snapshotStreams returns the currently registered streams.
+func (pc *pooledConn) snapshotStreams() []*http2Conn {
+	pc.streamsMu.Lock()
+	defer pc.streamsMu.Unlock()
+	out := make([]*http2Conn, 0, len(pc.streams))
+	for hc := range pc.streams {
+		out = append(out, hc)
+	}
+	return out
+}
+
+type http2DialerOpts struct {
+	// TLSConfig, if set, enables TLS (and, with Certificates set, mTLS) for
+	// connections made by the dialer. Without it, the dialer speaks
+	// cleartext HTTP/2 (h2c).
+	TLSConfig *tls.Config
+
+	MaxConnsPerPeer      int
+	MaxConcurrentStreams uint32
+	IdleConnTimeout      time.Duration
+
+	KeepaliveInterval   time.Duration
+	KeepaliveTimeout    time.Duration
+	PermitWithoutStream bool
+
+	Compression CompressionType
+}
+
+type HTTP2DialerOptFunc func(*http2DialerOpts)
+
+// WithHTTP2DialerTLSConfig configures the dialer to connect over TLS using
+// cfg (with "h2" added to NextProtos for ALPN negotiation). Setting
+// cfg.Certificates enables mTLS by presenting a client certificate.
+func WithHTTP2DialerTLSConfig(cfg *tls.Config) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.TLSConfig = cfg
+	}
+}
+
+// WithMaxConnsPerPeer caps how many pooled *http2.ClientConn the dialer
+// keeps open to a single remote address. Once the cap is reached, new
+// streams queue behind the existing connections' stream limits rather than
+// opening another one.
+func WithMaxConnsPerPeer(n int) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.MaxConnsPerPeer = n
+	}
+}
+
+// WithMaxConcurrentStreamsPerConn caps how many in-flight wire.Conn streams
+// the dialer will multiplex onto a single pooled *http2.ClientConn before
+// preferring to open another one (up to WithMaxConnsPerPeer).
+func WithMaxConcurrentStreamsPerConn(n uint32) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.MaxConcurrentStreams = n
+	}
+}
+
+// WithIdleConnTimeout sets how long a pooled connection may sit unused
+// before it's closed and evicted from the pool. A value of 0 disables idle
+// eviction.
+func WithIdleConnTimeout(d time.Duration) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.IdleConnTimeout = d
+	}
+}
+
+// WithKeepaliveInterval enables periodic HTTP/2 PING frames on each pooled
+// client connection, sent every d via the underlying *http2.ClientConn.Ping.
+// A value of 0 (the default) disables keepalive pings.
+func WithKeepaliveInterval(d time.Duration) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.KeepaliveInterval = d
+	}
+}
+
+// WithKeepaliveTimeout bounds how long a keepalive ping may take before the
+// peer is considered unresponsive: the pooled connection is closed and any
+// open streams on it fail their next Send/Recv with ErrPeerUnresponsive. A
+// value of 0 is replaced by defaultKeepaliveTimeout at NewHTTP2Dialer time
+// when a keepalive interval is set, and left at 0 otherwise.
+func WithKeepaliveTimeout(d time.Duration) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.KeepaliveTimeout = d
+	}
+}
+
+// WithPermitWithoutStream controls whether keepalive pings are sent on a
+// pooled connection that currently has no open streams. It defaults to
+// false, matching gRPC's keepalive semantics: an idle connection is left
+// alone rather than pinged (and possibly evicted) until something needs it.
+func WithPermitWithoutStream(permit bool) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.PermitWithoutStream = permit
+	}
+}
+
+// WithHTTP2DialerFrameCompression proposes compression for connections
+// opened by this dialer, via the Loki-Wire-Compression header. The
+// listener may not support the requested scheme, in which case it falls
+// back to CompressionNone and the dialer honors whatever it echoes back.
+func WithHTTP2DialerFrameCompression(compression CompressionType) HTTP2DialerOptFunc {
+	return func(o *http2DialerOpts) {
+		o.Compression = compression
+	}
+}
+
 // NewHTTP2Dialer creates a [Dialer] that can open HTTP/2 connections to the
-// specified address.
-func NewHTTP2Dialer(path string) *HTTP2Dialer {
+// specified address. By default it dials cleartext HTTP/2 (h2c); pass
+// WithHTTP2DialerTLSConfig to dial over TLS instead. It returns an error if
+// configured with a CompressionType that isn't actually implemented in this
+// build (see isSupportedCompression).
+func NewHTTP2Dialer(path string, optFuncs ...HTTP2DialerOptFunc) (*HTTP2Dialer, error) {
+	opts := http2DialerOpts{
+		MaxConnsPerPeer:      1,
+		MaxConcurrentStreams: 100,
+		IdleConnTimeout:      90 * time.Second,
+	}
+	for _, optFunc := range optFuncs {
+		optFunc(&opts)
+	}
+	if opts.KeepaliveInterval > 0 && opts.KeepaliveTimeout <= 0 {
+		opts.KeepaliveTimeout = defaultKeepaliveTimeout
+	}
+	if !isSupportedCompression(opts.Compression) {
+		return nil, fmt.Errorf("wire: unsupported compression scheme %s", opts.Compression)
+	}
+
+	transport := &http2.Transport{
+		AllowHTTP: opts.TLSConfig == nil,
+	}
+	if opts.TLSConfig != nil {
+		transport.TLSClientConfig = withALPN(opts.TLSConfig)
+	}
+
 	return &HTTP2Dialer{
-		client: &http.Client{
-			Transport: &http2.Transport{
-				// No TLS
-				AllowHTTP: true,
-				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
-					return (&net.Dialer{}).DialContext(ctx, network, addr)
-				},
-			},
-			// Context is used for cancellation, no timeout
-			Timeout: 0,
-		},
-		codec: defaultFrameCodec,
-		path:  path,
-	}
-}
-
-// Dial establishes an HTTP/2 connection to the specified address.
+		codec:               defaultFrameCodec,
+		path:                path,
+		tlsConfig:           opts.TLSConfig,
+		transport:           transport,
+		maxConnsPerPeer:     opts.MaxConnsPerPeer,
+		maxStreamsPerConn:   opts.MaxConcurrentStreams,
+		idleConnTimeout:     opts.IdleConnTimeout,
+		keepaliveInterval:   opts.KeepaliveInterval,
+		keepaliveTimeout:    opts.KeepaliveTimeout,
+		permitWithoutStream: opts.PermitWithoutStream,
+		compression:         opts.Compression,
+		peers:               make(map[string]*peerPool),
+	}, nil
+}
+
+// Dial establishes an HTTP/2 stream to the specified address, reusing a
+// pooled *http2.ClientConn for that peer when one with spare stream
+// capacity is available.
 func (d *HTTP2Dialer) Dial(ctx context.Context, from, to net.Addr) (Conn, error) {
+	addr := to.String()
+
+	pc, err := d.acquireClientConn(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("acquire client connection: %w", err)
+	}
+	atomic.AddInt32(&pc.active, 1)
+	released := false
+	release := func() {
+		if !released {
+			released = true
+			atomic.AddInt32(&pc.active, -1)
+		}
+	}
+
 	pr, pw := io.Pipe()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("http://%s%s", to.String(), d.path), pr)
+	scheme := "http"
+	if d.tlsConfig != nil {
+		scheme = "https"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s://%s%s", scheme, addr, d.path), pr)
 	if err != nil {
+		release()
+		_ = pw.Close()
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set(peerAddressHeader, from.String())
+	req.Header.Set(compressionHeader, d.compression.String())
 
-	resp, err := d.client.Do(req)
+	resp, err := pc.cc.RoundTrip(req)
 	if err != nil {
+		release()
 		_ = pw.Close()
+		d.invalidate(addr, pc)
 		return nil, err
 	}
 
@@ -353,9 +789,14 @@ func (d *HTTP2Dialer) Dial(ctx context.Context, from, to net.Addr) (Conn, error)
 		_, _ = io.Copy(io.Discard, resp.Body)
 		_ = resp.Body.Close()
 		_ = pw.Close()
+		release()
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
+	// The listener echoes back the compression scheme it actually applied,
+	// which may differ from what was proposed if it didn't recognize it.
+	compression := parseCompressionType(resp.Header.Get(compressionHeader))
+
 	// Create connection
 	conn := newHTTP2Conn(
 		req.Context(),
@@ -364,8 +805,12 @@ func (d *HTTP2Dialer) Dial(ctx context.Context, from, to net.Addr) (Conn, error)
 		resp.Body,
 		pw,
 		nil, // client doesn't need responseController, it's handled by the pipe writer
-		d.codec,
+		newFrameCodec(d.codec, compression),
 	)
+	conn.peerIdentity = peerIdentityFromTLS(resp.TLS)
+	conn.compression = compression
+
+	pc.addStream(conn)
 
 	readLoopWg := sync.WaitGroup{}
 	readLoopWg.Add(1)
@@ -374,15 +819,214 @@ func (d *HTTP2Dialer) Dial(ctx context.Context, from, to net.Addr) (Conn, error)
 		conn.readLoop(ctx)
 	}()
 
-	// when the connection is closed, close the pipe writer and wait until the reader loop exits
+	// when the connection is closed, close the pipe writer, wait until the
+	// reader loop exits, and release this stream's claim on the pooled conn
 	conn.cleanup = func() {
 		_ = pw.Close()
 		readLoopWg.Wait()
+		pc.removeStream(conn)
+		release()
 	}
 
 	return conn, nil
 }
 
+// acquireClientConn returns a pooled *http2.ClientConn for addr with spare
+// stream capacity, dialing and handshaking a new one if the pool for addr
+// is below maxConnsPerPeer, or reusing the least-loaded existing one
+// otherwise.
+func (d *HTTP2Dialer) acquireClientConn(ctx context.Context, addr string) (*pooledConn, error) {
+	pool := d.peerPoolFor(addr)
+
+	pool.mu.Lock()
+	for i := 0; i < len(pool.conns); i++ {
+		pc := pool.conns[i]
+		if !pc.cc.CanTakeNewRequest() {
+			pool.conns = append(pool.conns[:i], pool.conns[i+1:]...)
+			i--
+			continue
+		}
+		if atomic.LoadInt32(&pc.active) < int32(d.maxStreamsPerConn) {
+			pc.lastUsed = time.Now()
+			pool.mu.Unlock()
+			return pc, nil
+		}
+	}
+	if len(pool.conns) >= d.maxConnsPerPeer && len(pool.conns) > 0 {
+		// At capacity: queue the new stream on the conn with the most spare
+		// room rather than growing the pool further.
+		best := pool.conns[0]
+		for _, pc := range pool.conns[1:] {
+			if atomic.LoadInt32(&pc.active) < atomic.LoadInt32(&best.active) {
+				best = pc
+			}
+		}
+		pool.mu.Unlock()
+		return best, nil
+	}
+	pool.mu.Unlock()
+
+	rawConn, err := d.dialRaw(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := d.transport.NewClientConn(rawConn)
+	if err != nil {
+		_ = rawConn.Close()
+		return nil, err
+	}
+
+	pc := &pooledConn{cc: cc, lastUsed: time.Now()}
+	pool.mu.Lock()
+	pool.conns = append(pool.conns, pc)
+	pool.mu.Unlock()
+	d.scheduleIdleEviction(pool, pc)
+	d.startKeepalive(pool, pc)
+
+	return pc, nil
+}
+
+// startKeepalive, if a keepalive interval is configured, periodically pings
+// pc's underlying *http2.ClientConn. A successful ping updates LastPing on
+// every stream multiplexed onto pc; a timed-out ping closes pc, evicts it
+// from pool, and marks those streams unresponsive so their next Send/Recv
+// returns ErrPeerUnresponsive instead of waiting on a dead connection.
+func (d *HTTP2Dialer) startKeepalive(pool *peerPool, pc *pooledConn) {
+	if d.keepaliveInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(d.keepaliveInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			pool.mu.Lock()
+			stillPooled := slices.Contains(pool.conns, pc)
+			pool.mu.Unlock()
+			if !stillPooled {
+				return
+			}
+
+			if !d.permitWithoutStream && atomic.LoadInt32(&pc.active) == 0 {
+				continue
+			}
+
+			pingCtx, cancel := context.WithTimeout(context.Background(), d.keepaliveTimeout)
+			err := pc.cc.Ping(pingCtx)
+			cancel()
+
+			if err != nil {
+				pool.mu.Lock()
+				if idx := slices.Index(pool.conns, pc); idx >= 0 {
+					pool.conns = append(pool.conns[:idx], pool.conns[idx+1:]...)
+				}
+				pool.mu.Unlock()
+
+				_ = pc.cc.Close()
+				for _, stream := range pc.snapshotStreams() {
+					stream.markUnresponsive()
+				}
+				return
+			}
+
+			now := time.Now()
+			for _, stream := range pc.snapshotStreams() {
+				stream.recordPing(now)
+			}
+		}
+	}()
+}
+
+// dialRaw opens the raw transport connection underneath a pooled
+// *http2.ClientConn: a plain TCP dial for h2c, or a TCP dial followed by a
+// TLS handshake (with ALPN negotiating "h2") when tlsConfig is set.
+func (d *HTTP2Dialer) dialRaw(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if d.tlsConfig == nil {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, withALPN(d.tlsConfig))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("tls handshake: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// scheduleIdleEviction closes and removes pc from pool once it has sat
+// unused for longer than idleConnTimeout. A single check could otherwise
+// only ever catch pc while it happened to be idle at exactly that instant:
+// if it was active (or had just been used) at that one check, the timer
+// would fire once and never again, leaving the connection pooled for the
+// rest of the process's life even once it truly went idle later. So every
+// check that doesn't evict re-arms itself for whenever pc could plausibly
+// be idle next, rather than firing only once.
+func (d *HTTP2Dialer) scheduleIdleEviction(pool *peerPool, pc *pooledConn) {
+	if d.idleConnTimeout <= 0 {
+		return
+	}
+
+	var check func()
+	check = func() {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+
+		if atomic.LoadInt32(&pc.active) > 0 {
+			// Still serving streams; look again after another full
+			// timeout once it (hopefully) isn't.
+			time.AfterFunc(d.idleConnTimeout, check)
+			return
+		}
+		if idle := time.Since(pc.lastUsed); idle < d.idleConnTimeout {
+			// Used more recently than one timeout ago; re-check once the
+			// remaining idle time has actually elapsed.
+			time.AfterFunc(d.idleConnTimeout-idle, check)
+			return
+		}
+
+		for i, c := range pool.conns {
+			if c == pc {
+				pool.conns = append(pool.conns[:i], pool.conns[i+1:]...)
+				break
+			}
+		}
+		_ = pc.cc.Close()
+	}
+	time.AfterFunc(d.idleConnTimeout, check)
+}
+
+// invalidate drops pc from addr's pool after a failed RoundTrip, so the
+// next Dial to addr transparently redials instead of reusing a dead
+// connection.
+func (d *HTTP2Dialer) invalidate(addr string, pc *pooledConn) {
+	pool := d.peerPoolFor(addr)
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for i, c := range pool.conns {
+		if c == pc {
+			pool.conns = append(pool.conns[:i], pool.conns[i+1:]...)
+			break
+		}
+	}
+	_ = pc.cc.Close()
+}
+
+func (d *HTTP2Dialer) peerPoolFor(addr string) *peerPool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p, ok := d.peers[addr]
+	if !ok {
+		p = &peerPool{}
+		d.peers[addr] = p
+	}
+	return p
+}
+
 // isStreamClosedError returns true if err represents an `http2: stream closed`
 // error.
 func isStreamClosedError(err error) bool {
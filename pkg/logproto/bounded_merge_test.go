@@ -0,0 +1,102 @@
+package logproto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sourceFromLabelResponses(responses []*LabelResponse) LabelResponseSource {
+	i := 0
+	return func() (*LabelResponse, bool, error) {
+		if i >= len(responses) {
+			return nil, false, nil
+		}
+		resp := responses[i]
+		i++
+		return resp, true, nil
+	}
+}
+
+func sourceFromSeriesResponses(responses []*SeriesResponse) SeriesResponseSource {
+	i := 0
+	return func() (*SeriesResponse, bool, error) {
+		if i >= len(responses) {
+			return nil, false, nil
+		}
+		resp := responses[i]
+		i++
+		return resp, true, nil
+	}
+}
+
+func TestMergeLabelResponsesBoundedDedupesAndSorts(t *testing.T) {
+	next := sourceFromLabelResponses([]*LabelResponse{
+		{Values: []string{"b", "a"}},
+		{Values: []string{"a", "c"}},
+	})
+
+	got, err := MergeLabelResponsesBounded(next, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, got.Values)
+}
+
+func TestMergeLabelResponsesBoundedStopsAtLimit(t *testing.T) {
+	calls := 0
+	responses := []*LabelResponse{
+		{Values: []string{"a", "b"}},
+		{Values: []string{"c", "d"}},
+	}
+	next := func() (*LabelResponse, bool, error) {
+		calls++
+		return sourceFromLabelResponses(responses)()
+	}
+
+	got, err := MergeLabelResponsesBounded(next, 1)
+	require.NoError(t, err)
+	require.Len(t, got.Values, 1)
+	require.Equal(t, 1, calls, "must stop pulling from next once the limit is reached")
+}
+
+func TestMergeLabelResponsesBoundedPropagatesSourceError(t *testing.T) {
+	wantErr := errors.New("upstream unavailable")
+	next := func() (*LabelResponse, bool, error) {
+		return nil, false, wantErr
+	}
+
+	_, err := MergeLabelResponsesBounded(next, 0)
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestMergeSeriesResponsesBoundedStopsAtLimit(t *testing.T) {
+	next := sourceFromSeriesResponses([]*SeriesResponse{
+		{Series: []SeriesIdentifier{{}, {}}},
+		{Series: []SeriesIdentifier{{}, {}}},
+	})
+
+	got, err := MergeSeriesResponsesBounded(next, 3)
+	require.NoError(t, err)
+	require.Len(t, got.Series, 3)
+}
+
+func TestMergeSeriesResponsesBoundedUnlimitedCollectsAll(t *testing.T) {
+	next := sourceFromSeriesResponses([]*SeriesResponse{
+		{Series: []SeriesIdentifier{{}}},
+		{Series: []SeriesIdentifier{{}, {}}},
+	})
+
+	got, err := MergeSeriesResponsesBounded(next, 0)
+	require.NoError(t, err)
+	require.Len(t, got.Series, 3)
+}
+
+func TestMergeSeriesResponsesBoundedPropagatesSourceError(t *testing.T) {
+	wantErr := errors.New("upstream unavailable")
+	next := func() (*SeriesResponse, bool, error) {
+		return nil, false, wantErr
+	}
+
+	_, err := MergeSeriesResponsesBounded(next, 0)
+	require.ErrorIs(t, err, wantErr)
+}
@@ -1,6 +1,7 @@
 package logproto
 
 import (
+	"context"
 	"encoding/binary"
 	stdjson "encoding/json"
 	"fmt"
@@ -46,6 +47,65 @@ func ToWriteRequest(lbls []labels.Labels, samples []LegacySample, metadata []*Me
 	return req
 }
 
+// SampleWithCreatedTimestamp pairs a LegacySample with the created timestamp
+// of the series it belongs to, following the Prometheus "created timestamps
+// from OTLP start times" pattern. Query engines can use CreatedTimestampMs to
+// emit a synthetic zero sample at the creation timestamp for counters,
+// eliminating the "first scrape resets" gap when Loki ingests OTLP
+// metric-like signals.
+type SampleWithCreatedTimestamp struct {
+	LegacySample
+	// CreatedTimestampMs is the OTLP start time of the series, in
+	// milliseconds since the epoch. Zero means unknown/unset.
+	CreatedTimestampMs int64
+}
+
+// ToWriteRequestWithCreatedTimestamps behaves like ToWriteRequest, taking
+// samples paired with per-series created timestamps (milliseconds since the
+// epoch, 0 if unknown). Only the LegacySample part of each pair is written;
+// the created timestamps are not carried into the returned WriteRequest.
+func ToWriteRequestWithCreatedTimestamps(lbls []labels.Labels, samples []SampleWithCreatedTimestamp, metadata []*MetricMetadata, source WriteRequest_SourceEnum) *WriteRequest {
+	plain := make([]LegacySample, len(samples))
+	for i, s := range samples {
+		plain[i] = s.LegacySample
+	}
+	return ToWriteRequest(lbls, plain, metadata, source)
+}
+
+// MarshalJSON implements json.Marshaler. The created timestamp is encoded as
+// a third tuple element only when non-zero, so the wire format remains
+// compatible with consumers that only understand plain LegacySample tuples.
+func (s SampleWithCreatedTimestamp) MarshalJSON() ([]byte, error) {
+	legacy, err := s.LegacySample.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if s.CreatedTimestampMs == 0 {
+		return legacy, nil
+	}
+	return append(legacy[:len(legacy)-1], []byte(fmt.Sprintf(",%d]", s.CreatedTimestampMs))...), nil
+}
+
+// SampleWithCreatedTimestampJsoniterEncode mirrors SampleJsoniterEncode but
+// appends CreatedTimestampMs as a third tuple element when non-zero.
+func SampleWithCreatedTimestampJsoniterEncode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	s := (*SampleWithCreatedTimestamp)(ptr)
+
+	stream.WriteArrayStart()
+	stream.WriteFloat64(float64(s.TimestampMs) / float64(time.Second/time.Millisecond))
+	stream.WriteMore()
+	stream.WriteString(model.SampleValue(s.Value).String())
+	if s.CreatedTimestampMs != 0 {
+		stream.WriteMore()
+		stream.WriteInt64(s.CreatedTimestampMs)
+	}
+	stream.WriteArrayEnd()
+}
+
+func init() {
+	jsoniter.RegisterTypeEncoderFunc("logproto.SampleWithCreatedTimestamp", SampleWithCreatedTimestampJsoniterEncode, func(unsafe.Pointer) bool { return false })
+}
+
 // labelsZeroValue is the zero value of [labels.Labels]. If Loki is built with
 // Prometheus' slicelabels, the zero value of labels is a nil slice. This
 // contradicts to [labels.EmptyLabels], where it returns a non-nil slice with a
@@ -231,6 +291,161 @@ func SampleJsoniterDecode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 func init() {
 	jsoniter.RegisterTypeEncoderFunc("logproto.LegacySample", SampleJsoniterEncode, func(unsafe.Pointer) bool { return false })
 	jsoniter.RegisterTypeDecoderFunc("logproto.LegacySample", SampleJsoniterDecode)
+	jsoniter.RegisterTypeEncoderFunc("logproto.HistogramSample", HistogramSampleJsoniterEncode, func(unsafe.Pointer) bool { return false })
+	jsoniter.RegisterTypeDecoderFunc("logproto.HistogramSample", HistogramSampleJsoniterDecode)
+}
+
+// HistogramSample carries a Prometheus-style native histogram value for a single
+// timestamp, mirroring model.SampleHistogramPair but on the wire format used by
+// PreallocTimeseries. It supports both exponential (sparse bucket) and custom
+// bucket layouts via Schema, in the same way as prompb.Histogram.
+type HistogramSample struct {
+	TimestampMs int64
+
+	// Schema is the bucket schema, following Prometheus' native histogram
+	// convention: values >= 0 select a base-2 exponential schema, while -4 and
+	// -8 select custom bucket boundaries.
+	Schema int32
+
+	ZeroThreshold float64
+	ZeroCount     float64
+
+	Count float64
+	Sum   float64
+
+	// PositiveSpans/NegativeSpans describe runs of non-zero buckets as
+	// (offset, length) pairs relative to the previous span, and
+	// PositiveDeltas/NegativeDeltas carry the delta-encoded bucket counts for
+	// each span, matching the sparse representation used by Prometheus.
+	PositiveSpans  []HistogramSpan
+	PositiveDeltas []float64
+	NegativeSpans  []HistogramSpan
+	NegativeDeltas []float64
+}
+
+// HistogramSpan is a run of Length consecutive buckets starting Offset buckets
+// after the previous span (or after bucket 0 for the first span).
+type HistogramSpan struct {
+	Offset int32
+	Length uint32
+}
+
+// HistogramSeries pairs a label set with its HistogramSample points, mirroring
+// the relationship between Labels and Samples on TimeSeries.
+type HistogramSeries struct {
+	Labels     []LabelAdapter
+	Histograms []HistogramSample
+}
+
+// histogramValueJSON carries HistogramSample's fields other than
+// TimestampMs, which is encoded separately as the tuple's first element.
+// It's a plain, unregistered type so encoding/decoding it via
+// stream.WriteVal/iter.ReadVal falls through to jsoniter's ordinary
+// reflection-based (de)serialization instead of re-entering
+// HistogramSampleJsoniterEncode/Decode, which are registered against
+// HistogramSample itself.
+type histogramValueJSON struct {
+	Schema         int32
+	ZeroThreshold  float64
+	ZeroCount      float64
+	Count          float64
+	Sum            float64
+	PositiveSpans  []HistogramSpan
+	PositiveDeltas []float64
+	NegativeSpans  []HistogramSpan
+	NegativeDeltas []float64
+}
+
+// HistogramSampleJsoniterEncode encodes a HistogramSample in the same tuple
+// shape as the JSON API result format for float samples, except the value
+// position holds an object with the histogram fields so histograms round-trip
+// through the query frontend without lossy bucket approximations.
+func HistogramSampleJsoniterEncode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	h := (*HistogramSample)(ptr)
+
+	stream.WriteArrayStart()
+	stream.WriteFloat64(float64(h.TimestampMs) / float64(time.Second/time.Millisecond))
+	stream.WriteMore()
+	stream.WriteVal(histogramValueJSON{
+		Schema:         h.Schema,
+		ZeroThreshold:  h.ZeroThreshold,
+		ZeroCount:      h.ZeroCount,
+		Count:          h.Count,
+		Sum:            h.Sum,
+		PositiveSpans:  h.PositiveSpans,
+		PositiveDeltas: h.PositiveDeltas,
+		NegativeSpans:  h.NegativeSpans,
+		NegativeDeltas: h.NegativeDeltas,
+	})
+	stream.WriteArrayEnd()
+}
+
+// HistogramSampleJsoniterDecode decodes a HistogramSample encoded by
+// HistogramSampleJsoniterEncode.
+func HistogramSampleJsoniterDecode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	if !iter.ReadArray() {
+		iter.ReportError("logproto.HistogramSample", "expected [")
+		return
+	}
+
+	t := model.Time(iter.ReadFloat64() * float64(time.Second/time.Millisecond))
+
+	if !iter.ReadArray() {
+		iter.ReportError("logproto.HistogramSample", "expected ,")
+		return
+	}
+
+	var v histogramValueJSON
+	iter.ReadVal(&v)
+
+	if iter.ReadArray() {
+		iter.ReportError("logproto.HistogramSample", "expected ]")
+	}
+
+	*(*HistogramSample)(ptr) = HistogramSample{
+		TimestampMs:    int64(t),
+		Schema:         v.Schema,
+		ZeroThreshold:  v.ZeroThreshold,
+		ZeroCount:      v.ZeroCount,
+		Count:          v.Count,
+		Sum:            v.Sum,
+		PositiveSpans:  v.PositiveSpans,
+		PositiveDeltas: v.PositiveDeltas,
+		NegativeSpans:  v.NegativeSpans,
+		NegativeDeltas: v.NegativeDeltas,
+	}
+}
+
+// MergeHistogramSeries merges a and b, which must share the same label set,
+// into a single HistogramSeries sorted by timestamp. It's the histogram
+// counterpart to appending two PreallocTimeseries' Samples together, letting
+// query frontends combine sharded histogram results without downcasting to
+// float.
+func MergeHistogramSeries(a, b HistogramSeries) HistogramSeries {
+	merged := HistogramSeries{
+		Labels:     a.Labels,
+		Histograms: make([]HistogramSample, 0, len(a.Histograms)+len(b.Histograms)),
+	}
+
+	i, j := 0, 0
+	for i < len(a.Histograms) && j < len(b.Histograms) {
+		switch {
+		case a.Histograms[i].TimestampMs < b.Histograms[j].TimestampMs:
+			merged.Histograms = append(merged.Histograms, a.Histograms[i])
+			i++
+		case a.Histograms[i].TimestampMs > b.Histograms[j].TimestampMs:
+			merged.Histograms = append(merged.Histograms, b.Histograms[j])
+			j++
+		default:
+			merged.Histograms = append(merged.Histograms, a.Histograms[i])
+			i++
+			j++
+		}
+	}
+	merged.Histograms = append(merged.Histograms, a.Histograms[i:]...)
+	merged.Histograms = append(merged.Histograms, b.Histograms[j:]...)
+
+	return merged
 }
 
 // Combine unique values from multiple LabelResponses into a single, sorted LabelResponse.
@@ -284,6 +499,142 @@ func MergeSeriesResponses(responses []*SeriesResponse) (*SeriesResponse, error)
 	return result, nil
 }
 
+// LabelResponseSource pulls the next LabelResponse from an upstream source,
+// returning ok=false once exhausted. It lets MergeLabelResponsesBounded merge
+// results as they arrive instead of requiring the full []*LabelResponse
+// slice to be materialized upfront.
+type LabelResponseSource func() (resp *LabelResponse, ok bool, err error)
+
+// MergeLabelResponsesBounded merges LabelResponses pulled from next,
+// de-duplicating values as in MergeLabelResponses, but stops pulling and
+// returns early once limit unique values have been collected. A limit <= 0
+// means unbounded, matching MergeLabelResponses' behaviour.
+func MergeLabelResponsesBounded(next LabelResponseSource, limit int) (*LabelResponse, error) {
+	unique := map[string]struct{}{}
+	result := &LabelResponse{}
+
+	for {
+		if limit > 0 && len(result.Values) >= limit {
+			break
+		}
+
+		resp, ok, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		for _, v := range resp.Values {
+			if _, seen := unique[v]; seen {
+				continue
+			}
+			unique[v] = struct{}{}
+			result.Values = append(result.Values, v)
+			if limit > 0 && len(result.Values) >= limit {
+				break
+			}
+		}
+	}
+
+	sort.Strings(result.Values)
+	return result, nil
+}
+
+// SeriesResponseSource pulls the next SeriesResponse from an upstream source,
+// returning ok=false once exhausted.
+type SeriesResponseSource func() (resp *SeriesResponse, ok bool, err error)
+
+// MergeSeriesResponsesBounded merges SeriesResponses pulled from next,
+// stopping early once limit series have been collected. A limit <= 0 means
+// unbounded, matching MergeSeriesResponses' behaviour.
+func MergeSeriesResponsesBounded(next SeriesResponseSource, limit int) (*SeriesResponse, error) {
+	result := &SeriesResponse{}
+
+	for {
+		if limit > 0 && len(result.Series) >= limit {
+			break
+		}
+
+		resp, ok, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		remaining := limit - len(result.Series)
+		if limit > 0 && remaining < len(resp.Series) {
+			result.Series = append(result.Series, resp.Series[:remaining]...)
+			break
+		}
+		result.Series = append(result.Series, resp.Series...)
+	}
+
+	return result, nil
+}
+
+// Span event names emitted by StartSpanEvent/EndSpanEvent across the request
+// lifecycle, so traces are self-describing without needing to correlate log
+// lines.
+const (
+	SpanEventCacheLookup   = "cache_lookup"
+	SpanEventShardPlanning = "shard_planning"
+	SpanEventCacheKey      = "cache_key_derivation"
+	SpanEventDedup         = "dedup"
+)
+
+// Span attribute keys shared by StartSpanEvent/EndSpanEvent/RecordDecision
+// implementations below.
+const (
+	attrCacheStatus     = "loki.cache.status"
+	attrShardsCount     = "loki.shards.count"
+	attrShardsBytesEach = "loki.shards.bytes_per_shard"
+	attrDecisionStage   = "loki.decision.stage"
+	attrDecisionReason  = "loki.decision.reason"
+)
+
+// startSpanEvent and endSpanEvent are shared helpers backing the
+// StartSpanEvent/EndSpanEvent methods below: they stamp a named OTel span
+// event on the same trace.Span passed to LogToSpan, rather than opening a new
+// tracer, so cache lookups, shard fan-out and dedup counts show up inline in
+// the existing query trace.
+func startSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	sp.AddEvent(name+"_start", trace.WithAttributes(attrs...))
+}
+
+func endSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	sp.AddEvent(name+"_end", trace.WithAttributes(attrs...))
+}
+
+// recordDecision stamps a span event describing a decision made at some stage
+// of request processing, e.g. recordDecision(sp, "cache_lookup", "miss").
+func recordDecision(sp trace.Span, stage, reason string) {
+	sp.AddEvent("decision", trace.WithAttributes(
+		attribute.String(attrDecisionStage, stage),
+		attribute.String(attrDecisionReason, reason),
+	))
+}
+
+// StartSpanEvent records the start of a named processing stage (e.g. cache
+// lookup, shard planning) on sp.
+func (m *IndexStatsRequest) StartSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	startSpanEvent(sp, name, attrs...)
+}
+
+// EndSpanEvent records the end of a named processing stage on sp.
+func (m *IndexStatsRequest) EndSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	endSpanEvent(sp, name, attrs...)
+}
+
+// RecordDecision records a stage/reason pair, e.g. a cache hit/miss or
+// dedup decision, on sp.
+func (m *IndexStatsRequest) RecordDecision(sp trace.Span, stage, reason string) {
+	recordDecision(sp, stage, reason)
+}
+
 // Satisfy definitions.Request
 
 // GetStart returns the start timestamp of the request in milliseconds.
@@ -387,6 +738,23 @@ func (m *VolumeRequest) LogToSpan(sp trace.Span) {
 	)
 }
 
+// StartSpanEvent records the start of a named processing stage (e.g. cache
+// lookup, shard planning) on sp.
+func (m *VolumeRequest) StartSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	startSpanEvent(sp, name, attrs...)
+}
+
+// EndSpanEvent records the end of a named processing stage on sp.
+func (m *VolumeRequest) EndSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	endSpanEvent(sp, name, attrs...)
+}
+
+// RecordDecision records a stage/reason pair, e.g. a cache hit/miss or
+// dedup decision, on sp.
+func (m *VolumeRequest) RecordDecision(sp trace.Span, stage, reason string) {
+	recordDecision(sp, stage, reason)
+}
+
 // Satisfy definitions.Request for FilterChunkRefRequest
 
 // GetStart returns the start timestamp of the request in milliseconds.
@@ -554,6 +922,23 @@ func (m *ShardsRequest) LogToSpan(sp trace.Span) {
 	)
 }
 
+// StartSpanEvent records the start of a named processing stage (e.g. cache
+// lookup, shard planning) on sp.
+func (m *ShardsRequest) StartSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	startSpanEvent(sp, name, attrs...)
+}
+
+// EndSpanEvent records the end of a named processing stage on sp.
+func (m *ShardsRequest) EndSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	endSpanEvent(sp, name, attrs...)
+}
+
+// RecordDecision records a stage/reason pair, e.g. a cache hit/miss or
+// dedup decision, on sp.
+func (m *ShardsRequest) RecordDecision(sp trace.Span, stage, reason string) {
+	recordDecision(sp, stage, reason)
+}
+
 func (m *DetectedFieldsRequest) GetCachingOptions() (res definitions.CachingOptions) { return }
 
 func (m *DetectedFieldsRequest) WithStartEnd(start, end time.Time) definitions.Request {
@@ -580,6 +965,23 @@ func (m *DetectedFieldsRequest) LogToSpan(sp trace.Span) {
 	)
 }
 
+// StartSpanEvent records the start of a named processing stage (e.g. cache
+// lookup, shard planning) on sp.
+func (m *DetectedFieldsRequest) StartSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	startSpanEvent(sp, name, attrs...)
+}
+
+// EndSpanEvent records the end of a named processing stage on sp.
+func (m *DetectedFieldsRequest) EndSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	endSpanEvent(sp, name, attrs...)
+}
+
+// RecordDecision records a stage/reason pair, e.g. a cache hit/miss or
+// dedup decision, on sp.
+func (m *DetectedFieldsRequest) RecordDecision(sp trace.Span, stage, reason string) {
+	recordDecision(sp, stage, reason)
+}
+
 func (m *QueryPatternsRequest) GetCachingOptions() (res definitions.CachingOptions) { return }
 
 func (m *QueryPatternsRequest) WithStartEnd(start, end time.Time) definitions.Request {
@@ -608,6 +1010,23 @@ func (m *QueryPatternsRequest) LogToSpan(sp trace.Span) {
 	)
 }
 
+// StartSpanEvent records the start of a named processing stage (e.g. cache
+// lookup, shard planning) on sp.
+func (m *QueryPatternsRequest) StartSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	startSpanEvent(sp, name, attrs...)
+}
+
+// EndSpanEvent records the end of a named processing stage on sp.
+func (m *QueryPatternsRequest) EndSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	endSpanEvent(sp, name, attrs...)
+}
+
+// RecordDecision records a stage/reason pair, e.g. a cache hit/miss or
+// dedup decision, on sp.
+func (m *QueryPatternsRequest) RecordDecision(sp trace.Span, stage, reason string) {
+	recordDecision(sp, stage, reason)
+}
+
 func (m *DetectedLabelsRequest) GetStep() int64 { return 0 }
 
 func (m *DetectedLabelsRequest) GetCachingOptions() (res definitions.CachingOptions) { return }
@@ -636,3 +1055,115 @@ func (m *DetectedLabelsRequest) LogToSpan(sp trace.Span) {
 		attribute.String("end", m.End.String()),
 	)
 }
+
+// StartSpanEvent records the start of a named processing stage (e.g. cache
+// lookup, shard planning) on sp.
+func (m *DetectedLabelsRequest) StartSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	startSpanEvent(sp, name, attrs...)
+}
+
+// EndSpanEvent records the end of a named processing stage on sp.
+func (m *DetectedLabelsRequest) EndSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue) {
+	endSpanEvent(sp, name, attrs...)
+}
+
+// RecordDecision records a stage/reason pair, e.g. a cache hit/miss or
+// dedup decision, on sp.
+func (m *DetectedLabelsRequest) RecordDecision(sp trace.Span, stage, reason string) {
+	recordDecision(sp, stage, reason)
+}
+
+// FanoutTarget identifies a single upstream cluster or storage backend a
+// fanned-out request should be sent to, along with the tenant ID to present
+// to that target. This lets a single logical request be dispatched to
+// several peer clusters as if they were one virtual store.
+type FanoutTarget struct {
+	// Name identifies the upstream cluster/target, e.g. "us-east" or a peer
+	// Loki instance's address.
+	Name string
+	// TenantID overrides the tenant ID used against this target. If empty,
+	// the tenant ID from the incoming request's context is used unchanged.
+	TenantID string
+}
+
+// FanoutRequest wraps a definitions.Request (e.g. FilterChunkRefRequest,
+// ShardsRequest, IndexStatsRequest, VolumeRequest) with an ordered list of
+// FanoutTargets it should be dispatched to. The ordering is significant: it
+// determines the order results are merged back in when a merge strategy
+// cares about precedence.
+type FanoutRequest struct {
+	definitions.Request
+	Targets []FanoutTarget
+}
+
+// GetQuery returns the wrapped request's query with the target set mixed in,
+// so that cache keys for the same query against different target sets don't
+// collide.
+func (f *FanoutRequest) GetQuery() string {
+	return fmt.Sprintf("%s/%d", f.Request.GetQuery(), hashFanoutTargets(f.Targets))
+}
+
+// WithStartEndForCache implements resultscache.Request, preserving per-target
+// fanout while delegating range narrowing (and, for FilterChunkRefRequest,
+// chunk-ref filtering) to the wrapped request.
+func (f *FanoutRequest) WithStartEndForCache(start, end time.Time) resultscache.Request {
+	cacheReq, ok := f.Request.(resultscache.Request)
+	if !ok {
+		clone := *f
+		clone.Request = f.Request.WithStartEnd(start, end)
+		return &clone
+	}
+	clone := *f
+	clone.Request = cacheReq.WithStartEndForCache(start, end)
+	return &clone
+}
+
+func hashFanoutTargets(targets []FanoutTarget) uint64 {
+	if len(targets) == 0 {
+		return 0
+	}
+	h := xxhash.New()
+	for _, t := range targets {
+		_, _ = h.WriteString(t.Name)
+		_, _ = h.WriteString("=")
+		_, _ = h.WriteString(t.TenantID)
+		_, _ = h.WriteString(";")
+	}
+	return h.Sum64()
+}
+
+// FanoutDispatcher fans a single logical request out to several backends,
+// issuing one call per FanoutTarget and merging the responses back into a
+// single logical response.
+type FanoutDispatcher struct {
+	// Do issues req against the named target and returns its response.
+	Do func(ctx context.Context, target FanoutTarget, req definitions.Request) (*LabelResponse, *SeriesResponse, error)
+}
+
+// DispatchLabels fans req out across the request's targets and merges the
+// resulting LabelResponses using MergeLabelResponses.
+func (d *FanoutDispatcher) DispatchLabels(ctx context.Context, req *FanoutRequest) (*LabelResponse, error) {
+	responses := make([]*LabelResponse, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		resp, _, err := d.Do(ctx, target, req.Request)
+		if err != nil {
+			return nil, fmt.Errorf("fanout to target %s: %w", target.Name, err)
+		}
+		responses = append(responses, resp)
+	}
+	return MergeLabelResponses(responses)
+}
+
+// DispatchSeries fans req out across the request's targets and merges the
+// resulting SeriesResponses using MergeSeriesResponses.
+func (d *FanoutDispatcher) DispatchSeries(ctx context.Context, req *FanoutRequest) (*SeriesResponse, error) {
+	responses := make([]*SeriesResponse, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		_, resp, err := d.Do(ctx, target, req.Request)
+		if err != nil {
+			return nil, fmt.Errorf("fanout to target %s: %w", target.Name, err)
+		}
+		responses = append(responses, resp)
+	}
+	return MergeSeriesResponses(responses)
+}
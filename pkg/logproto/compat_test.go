@@ -0,0 +1,88 @@
+package logproto
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramSampleJsoniterRoundTrip(t *testing.T) {
+	h := HistogramSample{
+		TimestampMs:    1234,
+		Schema:         3,
+		ZeroThreshold:  0.001,
+		ZeroCount:      2,
+		Count:          10,
+		Sum:            42.5,
+		PositiveSpans:  []HistogramSpan{{Offset: 0, Length: 2}},
+		PositiveDeltas: []float64{1, -1},
+		NegativeSpans:  []HistogramSpan{{Offset: 1, Length: 1}},
+		NegativeDeltas: []float64{1},
+	}
+
+	b, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(h)
+	require.NoError(t, err)
+
+	var got HistogramSample
+	require.NoError(t, jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(b, &got))
+	require.Equal(t, h, got)
+}
+
+func TestMergeHistogramSeriesInterleavesByTimestamp(t *testing.T) {
+	labels := []LabelAdapter{{Name: "foo", Value: "bar"}}
+	a := HistogramSeries{
+		Labels: labels,
+		Histograms: []HistogramSample{
+			{TimestampMs: 1, Count: 1},
+			{TimestampMs: 3, Count: 3},
+		},
+	}
+	b := HistogramSeries{
+		Labels: labels,
+		Histograms: []HistogramSample{
+			{TimestampMs: 2, Count: 2},
+			{TimestampMs: 4, Count: 4},
+		},
+	}
+
+	merged := MergeHistogramSeries(a, b)
+	require.Equal(t, labels, merged.Labels)
+
+	var gotTimestamps []int64
+	for _, h := range merged.Histograms {
+		gotTimestamps = append(gotTimestamps, h.TimestampMs)
+	}
+	require.Equal(t, []int64{1, 2, 3, 4}, gotTimestamps)
+}
+
+func TestMergeHistogramSeriesDedupesSharedTimestamp(t *testing.T) {
+	labels := []LabelAdapter{{Name: "foo", Value: "bar"}}
+	a := HistogramSeries{
+		Labels:     labels,
+		Histograms: []HistogramSample{{TimestampMs: 5, Count: 1}},
+	}
+	b := HistogramSeries{
+		Labels:     labels,
+		Histograms: []HistogramSample{{TimestampMs: 5, Count: 2}},
+	}
+
+	merged := MergeHistogramSeries(a, b)
+	require.Len(t, merged.Histograms, 1)
+	require.Equal(t, a.Histograms[0], merged.Histograms[0])
+}
+
+func TestMergeHistogramSeriesAppendsRemainingTail(t *testing.T) {
+	labels := []LabelAdapter{{Name: "foo", Value: "bar"}}
+	a := HistogramSeries{
+		Labels:     labels,
+		Histograms: []HistogramSample{{TimestampMs: 1}, {TimestampMs: 2}, {TimestampMs: 3}},
+	}
+	b := HistogramSeries{
+		Labels:     labels,
+		Histograms: []HistogramSample{{TimestampMs: 1}},
+	}
+
+	merged := MergeHistogramSeries(a, b)
+	require.Len(t, merged.Histograms, 3)
+}
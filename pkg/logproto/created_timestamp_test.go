@@ -0,0 +1,57 @@
+package logproto
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleWithCreatedTimestampMarshalJSONOmitsZeroCreatedTimestamp(t *testing.T) {
+	s := SampleWithCreatedTimestamp{LegacySample: LegacySample{TimestampMs: 1000, Value: 1.5}}
+
+	b, err := s.MarshalJSON()
+	require.NoError(t, err)
+
+	legacy, err := s.LegacySample.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, string(legacy), string(b))
+}
+
+func TestSampleWithCreatedTimestampMarshalJSONIncludesNonZeroCreatedTimestamp(t *testing.T) {
+	s := SampleWithCreatedTimestamp{
+		LegacySample:       LegacySample{TimestampMs: 1000, Value: 1.5},
+		CreatedTimestampMs: 500,
+	}
+
+	b, err := s.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `[1,"1.5",500]`, string(b))
+}
+
+func TestSampleWithCreatedTimestampJsoniterEncodeMatchesMarshalJSON(t *testing.T) {
+	for _, s := range []SampleWithCreatedTimestamp{
+		{LegacySample: LegacySample{TimestampMs: 1000, Value: 1.5}},
+		{LegacySample: LegacySample{TimestampMs: 1000, Value: 1.5}, CreatedTimestampMs: 500},
+	} {
+		want, err := s.MarshalJSON()
+		require.NoError(t, err)
+
+		got, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(s)
+		require.NoError(t, err)
+		require.Equal(t, string(want), string(got))
+	}
+}
+
+func TestToWriteRequestWithCreatedTimestampsDelegatesToToWriteRequest(t *testing.T) {
+	lbls := []labels.Labels{labels.FromStrings("foo", "bar")}
+	samples := []SampleWithCreatedTimestamp{
+		{LegacySample: LegacySample{TimestampMs: 1000, Value: 1.5}, CreatedTimestampMs: 500},
+	}
+
+	req := ToWriteRequestWithCreatedTimestamps(lbls, samples, nil, WriteRequest_SourceEnum(0))
+
+	require.Len(t, req.Timeseries, 1)
+	require.Equal(t, []LegacySample{samples[0].LegacySample}, req.Timeseries[0].Samples)
+}
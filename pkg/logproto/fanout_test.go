@@ -0,0 +1,158 @@
+package logproto
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache/resultscache"
+)
+
+// fakeRequest is a minimal definitions.Request used to exercise
+// FanoutRequest/FanoutDispatcher without depending on a concrete request
+// type like FilterChunkRefRequest.
+type fakeRequest struct {
+	query      string
+	start, end time.Time
+}
+
+func (f *fakeRequest) GetStart() time.Time                                 { return f.start }
+func (f *fakeRequest) GetEnd() time.Time                                   { return f.end }
+func (f *fakeRequest) GetStep() int64                                      { return 0 }
+func (f *fakeRequest) GetQuery() string                                    { return f.query }
+func (f *fakeRequest) GetCachingOptions() (res definitions.CachingOptions) { return }
+func (f *fakeRequest) WithStartEnd(start, end time.Time) definitions.Request {
+	clone := *f
+	clone.start, clone.end = start, end
+	return &clone
+}
+func (f *fakeRequest) WithQuery(query string) definitions.Request {
+	clone := *f
+	clone.query = query
+	return &clone
+}
+func (f *fakeRequest) LogToSpan(trace.Span) {}
+
+// fakeCacheableRequest additionally satisfies resultscache.Request, to
+// exercise FanoutRequest.WithStartEndForCache's type-asserted fast path.
+type fakeCacheableRequest struct {
+	fakeRequest
+}
+
+func (f *fakeCacheableRequest) WithStartEndForCache(start, end time.Time) resultscache.Request {
+	clone := *f
+	clone.start, clone.end = start, end
+	return &clone
+}
+
+var (
+	_ definitions.Request  = (*fakeRequest)(nil)
+	_ resultscache.Request = (*fakeCacheableRequest)(nil)
+)
+
+func TestFanoutRequestGetQueryIncludesTargetSet(t *testing.T) {
+	base := &fakeRequest{query: "{app=\"foo\"}"}
+
+	withA := &FanoutRequest{Request: base, Targets: []FanoutTarget{{Name: "us-east"}}}
+	withB := &FanoutRequest{Request: base, Targets: []FanoutTarget{{Name: "us-west"}}}
+	none := &FanoutRequest{Request: base}
+
+	require.NotEqual(t, withA.GetQuery(), withB.GetQuery(), "different target sets must not collide in the cache key")
+	require.NotEqual(t, withA.GetQuery(), none.GetQuery())
+	require.Contains(t, withA.GetQuery(), base.GetQuery())
+}
+
+func TestFanoutRequestGetQueryStableForSameTargets(t *testing.T) {
+	base := &fakeRequest{query: "{app=\"foo\"}"}
+	targets := []FanoutTarget{{Name: "us-east", TenantID: "tenant-a"}, {Name: "us-west"}}
+
+	a := &FanoutRequest{Request: base, Targets: targets}
+	b := &FanoutRequest{Request: base, Targets: targets}
+
+	require.Equal(t, a.GetQuery(), b.GetQuery())
+}
+
+func TestFanoutRequestWithStartEndForCacheUsesWrappedFastPath(t *testing.T) {
+	start := time.Unix(100, 0)
+	end := time.Unix(200, 0)
+	inner := &fakeCacheableRequest{fakeRequest{query: "q"}}
+
+	f := &FanoutRequest{Request: inner, Targets: []FanoutTarget{{Name: "us-east"}}}
+	got := f.WithStartEndForCache(start, end)
+
+	clone, ok := got.(*FanoutRequest)
+	require.True(t, ok)
+	require.Equal(t, f.Targets, clone.Targets)
+	require.Equal(t, start, clone.Request.GetStart())
+	require.Equal(t, end, clone.Request.GetEnd())
+}
+
+func TestFanoutRequestWithStartEndForCacheFallsBackWithoutCacheSupport(t *testing.T) {
+	start := time.Unix(100, 0)
+	end := time.Unix(200, 0)
+	inner := &fakeRequest{query: "q"}
+
+	f := &FanoutRequest{Request: inner, Targets: []FanoutTarget{{Name: "us-east"}}}
+	got := f.WithStartEndForCache(start, end)
+
+	clone, ok := got.(*FanoutRequest)
+	require.True(t, ok)
+	require.Equal(t, start, clone.Request.GetStart())
+	require.Equal(t, end, clone.Request.GetEnd())
+}
+
+func TestFanoutDispatcherDispatchLabelsMergesAllTargets(t *testing.T) {
+	d := &FanoutDispatcher{
+		Do: func(_ context.Context, target FanoutTarget, _ definitions.Request) (*LabelResponse, *SeriesResponse, error) {
+			return &LabelResponse{Values: []string{target.Name}}, nil, nil
+		},
+	}
+	req := &FanoutRequest{
+		Request: &fakeRequest{query: "q"},
+		Targets: []FanoutTarget{{Name: "a"}, {Name: "b"}},
+	}
+
+	resp, err := d.DispatchLabels(context.Background(), req)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, resp.Values)
+}
+
+func TestFanoutDispatcherDispatchLabelsPropagatesTargetError(t *testing.T) {
+	wantErr := errors.New("target unreachable")
+	d := &FanoutDispatcher{
+		Do: func(_ context.Context, target FanoutTarget, _ definitions.Request) (*LabelResponse, *SeriesResponse, error) {
+			if target.Name == "b" {
+				return nil, nil, wantErr
+			}
+			return &LabelResponse{Values: []string{target.Name}}, nil, nil
+		},
+	}
+	req := &FanoutRequest{
+		Request: &fakeRequest{query: "q"},
+		Targets: []FanoutTarget{{Name: "a"}, {Name: "b"}},
+	}
+
+	_, err := d.DispatchLabels(context.Background(), req)
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestFanoutDispatcherDispatchSeriesMergesAllTargets(t *testing.T) {
+	d := &FanoutDispatcher{
+		Do: func(_ context.Context, _ FanoutTarget, _ definitions.Request) (*LabelResponse, *SeriesResponse, error) {
+			return nil, &SeriesResponse{Series: []SeriesIdentifier{{}}}, nil
+		},
+	}
+	req := &FanoutRequest{
+		Request: &fakeRequest{query: "q"},
+		Targets: []FanoutTarget{{Name: "a"}, {Name: "b"}},
+	}
+
+	resp, err := d.DispatchSeries(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Series, 2)
+}
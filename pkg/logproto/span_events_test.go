@@ -0,0 +1,110 @@
+package logproto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordedEvent captures one AddEvent call made against a recordingSpan.
+type recordedEvent struct {
+	name  string
+	attrs []attribute.KeyValue
+}
+
+// recordingSpan is a minimal trace.Span that only records AddEvent calls,
+// for asserting on the span events StartSpanEvent/EndSpanEvent/RecordDecision
+// emit without depending on a real OTel SDK exporter.
+type recordingSpan struct {
+	events []recordedEvent
+}
+
+var _ trace.Span = (*recordingSpan)(nil)
+
+func (s *recordingSpan) AddEvent(name string, options ...trace.EventOption) {
+	cfg := trace.NewEventConfig(options...)
+	s.events = append(s.events, recordedEvent{name: name, attrs: cfg.Attributes()})
+}
+
+func (s *recordingSpan) End(...trace.SpanEndOption)              {}
+func (s *recordingSpan) AddLink(trace.Link)                      {}
+func (s *recordingSpan) IsRecording() bool                       { return true }
+func (s *recordingSpan) RecordError(error, ...trace.EventOption) {}
+func (s *recordingSpan) SpanContext() trace.SpanContext          { return trace.SpanContext{} }
+func (s *recordingSpan) SetStatus(codes.Code, string)            {}
+func (s *recordingSpan) SetName(string)                          {}
+func (s *recordingSpan) SetAttributes(...attribute.KeyValue)     {}
+func (s *recordingSpan) TracerProvider() trace.TracerProvider    { return trace.NewNoopTracerProvider() }
+
+func attrValue(t *testing.T, attrs []attribute.KeyValue, key string) attribute.Value {
+	t.Helper()
+	for _, a := range attrs {
+		if string(a.Key) == key {
+			return a.Value
+		}
+	}
+	t.Fatalf("attribute %q not found in %v", key, attrs)
+	return attribute.Value{}
+}
+
+func TestIndexStatsRequestStartEndSpanEventNaming(t *testing.T) {
+	sp := &recordingSpan{}
+	m := &IndexStatsRequest{}
+
+	m.StartSpanEvent(sp, SpanEventCacheLookup, attribute.String(attrCacheStatus, "miss"))
+	m.EndSpanEvent(sp, SpanEventCacheLookup, attribute.String(attrCacheStatus, "hit"))
+
+	require.Len(t, sp.events, 2)
+	require.Equal(t, SpanEventCacheLookup+"_start", sp.events[0].name)
+	require.Equal(t, SpanEventCacheLookup+"_end", sp.events[1].name)
+	require.Equal(t, "miss", attrValue(t, sp.events[0].attrs, attrCacheStatus).AsString())
+	require.Equal(t, "hit", attrValue(t, sp.events[1].attrs, attrCacheStatus).AsString())
+}
+
+func TestIndexStatsRequestRecordDecisionIncludesStageAndReason(t *testing.T) {
+	sp := &recordingSpan{}
+	m := &IndexStatsRequest{}
+
+	m.RecordDecision(sp, SpanEventCacheLookup, "miss")
+
+	require.Len(t, sp.events, 1)
+	require.Equal(t, "decision", sp.events[0].name)
+	require.Equal(t, SpanEventCacheLookup, attrValue(t, sp.events[0].attrs, attrDecisionStage).AsString())
+	require.Equal(t, "miss", attrValue(t, sp.events[0].attrs, attrDecisionReason).AsString())
+}
+
+// TestAllRequestTypesExposeSpanLifecycleHooks is a compile-time-flavored
+// smoke test that every Request implementation promised the span lifecycle
+// hooks, not just IndexStatsRequest, and that they all delegate to the same
+// shared helpers (same event names/attribute keys) rather than drifting.
+func TestAllRequestTypesExposeSpanLifecycleHooks(t *testing.T) {
+	type hooks interface {
+		StartSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue)
+		EndSpanEvent(sp trace.Span, name string, attrs ...attribute.KeyValue)
+		RecordDecision(sp trace.Span, stage, reason string)
+	}
+
+	reqs := []hooks{
+		&IndexStatsRequest{},
+		&VolumeRequest{},
+		&ShardsRequest{},
+		&DetectedFieldsRequest{},
+		&QueryPatternsRequest{},
+		&DetectedLabelsRequest{},
+	}
+
+	for _, r := range reqs {
+		sp := &recordingSpan{}
+		r.StartSpanEvent(sp, SpanEventShardPlanning)
+		r.EndSpanEvent(sp, SpanEventShardPlanning)
+		r.RecordDecision(sp, SpanEventDedup, "deduped")
+
+		require.Len(t, sp.events, 3)
+		require.Equal(t, SpanEventShardPlanning+"_start", sp.events[0].name)
+		require.Equal(t, SpanEventShardPlanning+"_end", sp.events[1].name)
+		require.Equal(t, "decision", sp.events[2].name)
+	}
+}
@@ -0,0 +1,270 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/prometheus/promql"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// QueryResult is a TestCase's result against one backend: Matrix for a
+// metric query, Streams for a log query. A TestCase of the other kind
+// leaves the unused field nil/empty.
+type QueryResult struct {
+	Matrix  promql.Matrix
+	Streams logqlmodel.Streams
+}
+
+// QueryRunner executes tc against bkt and returns its result. Executing a
+// LogQL query against a raw object-storage bucket requires the
+// dataobj/metastore-backed querier stack, so
+// CompareCorpus takes a QueryRunner from its caller -- e.g. querycomparator
+// wires one backed by the real querier -- rather than constructing one
+// itself.
+type QueryRunner func(ctx context.Context, bkt objstore.Bucket, tc TestCase) (QueryResult, error)
+
+// CompareOptions configures CompareCorpus.
+type CompareOptions struct {
+	// Run executes a TestCase against one backend bucket.
+	Run QueryRunner
+	// ValueTolerance is the maximum absolute difference allowed between two
+	// matched metric samples before they're reported as a MismatchValue. 0
+	// requires an exact match.
+	ValueTolerance float64
+}
+
+// MismatchKind classifies one discrepancy CompareCorpus found between the
+// two backends' results for a single TestCase.
+type MismatchKind string
+
+const (
+	// MismatchMissing marks a series/line present in backend A but absent
+	// from backend B.
+	MismatchMissing MismatchKind = "missing"
+	// MismatchExtra marks a series/line present in backend B but absent
+	// from backend A.
+	MismatchExtra MismatchKind = "extra"
+	// MismatchValue marks a metric sample present in both backends whose
+	// values differ by more than CompareOptions.ValueTolerance.
+	MismatchValue MismatchKind = "value"
+	// MismatchOrder marks a position in a stream where both backends have a
+	// log line but disagree on its timestamp or text, e.g. because the two
+	// backends disagree on tie-breaking for same-timestamp lines.
+	MismatchOrder MismatchKind = "order"
+)
+
+// Mismatch is one discrepancy found while comparing a single TestCase's two
+// results.
+type Mismatch struct {
+	Kind   MismatchKind
+	Detail string
+}
+
+// CaseReport is the comparison outcome for one TestCase.
+type CaseReport struct {
+	TestCase TestCase
+	Err      error // set if either backend failed to execute the query
+
+	Matched    int
+	Mismatched int
+	Missing    int
+	Extra      int
+	Mismatches []Mismatch
+}
+
+// OK reports whether this case's comparison found no discrepancies and
+// neither backend errored.
+func (r CaseReport) OK() bool {
+	return r.Err == nil && r.Mismatched == 0 && r.Missing == 0 && r.Extra == 0
+}
+
+// Report is the full result of CompareCorpus: one CaseReport per TestCase
+// plus the totals summed across all of them.
+type Report struct {
+	Cases []CaseReport
+
+	Matched    int
+	Mismatched int
+	Missing    int
+	Extra      int
+	Errored    int
+}
+
+// Summary returns a one-line human-readable tally, e.g. for a CLI to print
+// after a run.
+func (r Report) Summary() string {
+	return fmt.Sprintf("%d cases: %d matched, %d mismatched, %d missing, %d extra, %d errored",
+		len(r.Cases), r.Matched, r.Mismatched, r.Missing, r.Extra, r.Errored)
+}
+
+// CompareCorpus loads every TestCase under corpusDir via LoadCorpus, runs
+// each one against bktA and bktB using opts.Run, and diffs the two results:
+// metric query series are compared sample-by-sample within
+// opts.ValueTolerance, and log query streams are compared line-by-line for
+// both ordering and labels. The returned Report is safe to render directly
+// or marshal for further processing.
+func CompareCorpus(ctx context.Context, corpusDir string, bktA, bktB objstore.Bucket, opts CompareOptions) (*Report, error) {
+	cases, err := LoadCorpus(corpusDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, tc := range cases {
+		cr := compareCase(ctx, tc, bktA, bktB, opts)
+		report.Cases = append(report.Cases, cr)
+		report.Matched += cr.Matched
+		report.Mismatched += cr.Mismatched
+		report.Missing += cr.Missing
+		report.Extra += cr.Extra
+		if cr.Err != nil {
+			report.Errored++
+		}
+	}
+
+	return report, nil
+}
+
+func compareCase(ctx context.Context, tc TestCase, bktA, bktB objstore.Bucket, opts CompareOptions) CaseReport {
+	resA, errA := opts.Run(ctx, bktA, tc)
+	if errA != nil {
+		return CaseReport{TestCase: tc, Err: fmt.Errorf("backend A: %w", errA)}
+	}
+	resB, errB := opts.Run(ctx, bktB, tc)
+	if errB != nil {
+		return CaseReport{TestCase: tc, Err: fmt.Errorf("backend B: %w", errB)}
+	}
+
+	if tc.Kind() == "metric" {
+		return compareMatrix(tc, resA.Matrix, resB.Matrix, opts.ValueTolerance)
+	}
+	return compareStreams(tc, resA.Streams, resB.Streams)
+}
+
+// compareMatrix diffs two metric query results series-by-series, keyed by
+// each series' label set, and within a matched series sample-by-sample,
+// keyed by timestamp.
+func compareMatrix(tc TestCase, a, b promql.Matrix, tolerance float64) CaseReport {
+	cr := CaseReport{TestCase: tc}
+
+	bySeries := make(map[string]promql.Series, len(b))
+	for _, s := range b {
+		bySeries[s.Metric.String()] = s
+	}
+
+	for _, sa := range a {
+		key := sa.Metric.String()
+		sb, ok := bySeries[key]
+		if !ok {
+			cr.Missing++
+			cr.Mismatches = append(cr.Mismatches, Mismatch{
+				Kind:   MismatchMissing,
+				Detail: fmt.Sprintf("series %s present in A only", key),
+			})
+			continue
+		}
+		delete(bySeries, key)
+
+		byTime := make(map[int64]float64, len(sb.Floats))
+		for _, p := range sb.Floats {
+			byTime[p.T] = p.F
+		}
+		for _, pa := range sa.Floats {
+			pb, ok := byTime[pa.T]
+			if !ok {
+				cr.Missing++
+				cr.Mismatches = append(cr.Mismatches, Mismatch{
+					Kind:   MismatchMissing,
+					Detail: fmt.Sprintf("series %s: sample at t=%d present in A only", key, pa.T),
+				})
+				continue
+			}
+			if math.Abs(pa.F-pb) > tolerance {
+				cr.Mismatched++
+				cr.Mismatches = append(cr.Mismatches, Mismatch{
+					Kind:   MismatchValue,
+					Detail: fmt.Sprintf("series %s: sample at t=%d differs: A=%v B=%v", key, pa.T, pa.F, pb),
+				})
+				continue
+			}
+			cr.Matched++
+		}
+	}
+
+	// Whatever's left in bySeries was never matched against a series in A.
+	for key := range bySeries {
+		cr.Extra++
+		cr.Mismatches = append(cr.Mismatches, Mismatch{
+			Kind:   MismatchExtra,
+			Detail: fmt.Sprintf("series %s present in B only", key),
+		})
+	}
+
+	return cr
+}
+
+// compareStreams diffs two log query results stream-by-stream, keyed by
+// each stream's label string, and within a matched stream line-by-line in
+// order -- a line at the same position in both streams but with a
+// different timestamp or text is reported as MismatchOrder rather than
+// MismatchMissing/MismatchExtra, since it's the same slot disagreeing
+// rather than a line only one side has.
+func compareStreams(tc TestCase, a, b logqlmodel.Streams) CaseReport {
+	cr := CaseReport{TestCase: tc}
+
+	byLabels := make(map[string][]logproto.Entry, len(b))
+	for _, s := range b {
+		byLabels[s.Labels] = s.Entries
+	}
+
+	for _, sa := range a {
+		entriesA := sa.Entries
+		entriesB, ok := byLabels[sa.Labels]
+		if !ok {
+			cr.Missing += len(entriesA)
+			cr.Mismatches = append(cr.Mismatches, Mismatch{
+				Kind:   MismatchMissing,
+				Detail: fmt.Sprintf("stream %s present in A only (%d lines)", sa.Labels, len(entriesA)),
+			})
+			continue
+		}
+		delete(byLabels, sa.Labels)
+
+		n := len(entriesA)
+		if len(entriesB) < n {
+			n = len(entriesB)
+		}
+		for i := 0; i < n; i++ {
+			if entriesA[i].Timestamp.Equal(entriesB[i].Timestamp) && entriesA[i].Line == entriesB[i].Line {
+				cr.Matched++
+				continue
+			}
+			cr.Mismatched++
+			cr.Mismatches = append(cr.Mismatches, Mismatch{
+				Kind:   MismatchOrder,
+				Detail: fmt.Sprintf("stream %s: line %d differs: A=%+v B=%+v", sa.Labels, i, entriesA[i], entriesB[i]),
+			})
+		}
+		if len(entriesA) > n {
+			cr.Missing += len(entriesA) - n
+		}
+		if len(entriesB) > n {
+			cr.Extra += len(entriesB) - n
+		}
+	}
+
+	for labelStr, entries := range byLabels {
+		cr.Extra += len(entries)
+		cr.Mismatches = append(cr.Mismatches, Mismatch{
+			Kind:   MismatchExtra,
+			Detail: fmt.Sprintf("stream %s present in B only (%d lines)", labelStr, len(entries)),
+		})
+	}
+
+	return cr
+}
@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// caseSpec is the YAML shape of a single test case within a suite file.
+// Direction defaults to "forward" and Step to 0 (instant/log query) when
+// omitted.
+type caseSpec struct {
+	Query     string `yaml:"query"`
+	Start     string `yaml:"start"` // RFC3339
+	End       string `yaml:"end"`   // RFC3339
+	Direction string `yaml:"direction"`
+	Step      string `yaml:"step"` // parsed with time.ParseDuration
+	Desc      string `yaml:"desc"`
+}
+
+// suiteSpec is the YAML shape of one corpus file: a named group of related
+// cases, e.g. "label_filters.yaml" holding every case exercising label
+// matchers.
+type suiteSpec struct {
+	Name  string     `yaml:"name"`
+	Cases []caseSpec `yaml:"cases"`
+}
+
+// LoadCorpus reads every *.yaml/*.yml file directly under dir, parses each
+// as a suiteSpec, and flattens all of their cases into a single []TestCase.
+// Each case's Source is set to "<file>:<index>" (1-based) so a failure
+// reported by CompareCorpus can be traced back to the YAML that produced
+// it. Files are read in sorted filename order, and cases within a file in
+// the order they appear, so repeated runs produce a stable case ordering.
+func LoadCorpus(dir string) ([]TestCase, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read corpus dir %q: %w", dir, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(e.Name())
+		if ext == ".yaml" || ext == ".yml" {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+
+	var cases []TestCase
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read suite %q: %w", path, err)
+		}
+
+		var suite suiteSpec
+		if err := yaml.Unmarshal(b, &suite); err != nil {
+			return nil, fmt.Errorf("parse suite %q: %w", path, err)
+		}
+
+		for i, spec := range suite.Cases {
+			tc, err := spec.toTestCase(fmt.Sprintf("%s:%d", name, i+1))
+			if err != nil {
+				return nil, fmt.Errorf("suite %q case %d: %w", path, i+1, err)
+			}
+			cases = append(cases, tc)
+		}
+	}
+
+	return cases, nil
+}
+
+func (s caseSpec) toTestCase(source string) (TestCase, error) {
+	start, err := time.Parse(time.RFC3339, s.Start)
+	if err != nil {
+		return TestCase{}, fmt.Errorf("parse start %q: %w", s.Start, err)
+	}
+	end, err := time.Parse(time.RFC3339, s.End)
+	if err != nil {
+		return TestCase{}, fmt.Errorf("parse end %q: %w", s.End, err)
+	}
+
+	direction := logproto.FORWARD
+	if s.Direction == "backward" {
+		direction = logproto.BACKWARD
+	}
+
+	var step time.Duration
+	if s.Step != "" {
+		step, err = time.ParseDuration(s.Step)
+		if err != nil {
+			return TestCase{}, fmt.Errorf("parse step %q: %w", s.Step, err)
+		}
+	}
+
+	return TestCase{
+		Query:     s.Query,
+		Start:     start,
+		End:       end,
+		Direction: direction,
+		Step:      step,
+		Source:    source,
+		QueryDesc: s.Desc,
+	}, nil
+}
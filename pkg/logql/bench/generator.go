@@ -0,0 +1,265 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// LabelSchema describes one stream label a Generator may select for a
+// query's selector, and the values it may take on.
+type LabelSchema struct {
+	Name   string   `yaml:"name"`
+	Values []string `yaml:"values"`
+}
+
+// Schema is a stream label schema and value distribution a Generator draws
+// candidate queries from, loaded from YAML via LoadSchema.
+type Schema struct {
+	Labels []LabelSchema `yaml:"labels"`
+	// UnwrapFields are the structured-metadata/label field names available
+	// to an `| unwrap <field>` stage.
+	UnwrapFields []string `yaml:"unwrap_fields"`
+	// FilterTerms are candidate line-filter search terms for `|=`/`!=`
+	// stages.
+	FilterTerms []string `yaml:"filter_terms"`
+}
+
+// LoadSchema reads and parses a Schema from the YAML file at path.
+func LoadSchema(path string) (Schema, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Schema{}, fmt.Errorf("read schema %q: %w", path, err)
+	}
+	var s Schema
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return Schema{}, fmt.Errorf("parse schema %q: %w", path, err)
+	}
+	return s, nil
+}
+
+// Generator synthesizes TestCases covering a diverse mix of LogQL query
+// shapes -- log filters, line_format, label_format, unwrap, rate/bytes_rate/
+// sum by/topk, and multi-range subqueries -- drawing stream selectors and
+// filter/unwrap terms from Schema. Anchor is the fixed point in time
+// generated time windows are offset from, so that Generate(seed, n) with
+// the same seed and Anchor always reproduces the same TestCases.
+type Generator struct {
+	Schema Schema
+	Anchor time.Time
+}
+
+// NewGenerator returns a Generator drawing from schema, anchoring generated
+// time windows to end at or before anchor.
+func NewGenerator(schema Schema, anchor time.Time) *Generator {
+	return &Generator{Schema: schema, Anchor: anchor}
+}
+
+var generatorSteps = []time.Duration{15 * time.Second, time.Minute, 5 * time.Minute, time.Hour}
+
+var generatorRanges = []string{"1m", "5m", "15m", "1h"}
+
+// Generate synthesizes n valid TestCases from seed, skipping any candidate
+// whose query fails syntax.ParseExpr. Two calls with the same seed, n, and
+// Generator value produce identical output; each case's Source is set to
+// "generated:<seed>#<i>" (1-based) so a case that fails in the comparator
+// can be replayed by regenerating with the same seed and picking out that
+// index.
+//
+// Generate makes at most 20*n attempts before returning whatever it has
+// gathered so far, so a Schema too sparse to produce n valid queries
+// doesn't spin forever.
+func (g *Generator) Generate(seed int64, n int) []TestCase {
+	rnd := rand.New(rand.NewSource(seed))
+
+	var cases []TestCase
+	for attempt := 0; len(cases) < n && attempt < n*20; attempt++ {
+		query, ok := g.randomQuery(rnd)
+		if !ok {
+			continue
+		}
+		if _, err := syntax.ParseExpr(query); err != nil {
+			continue
+		}
+
+		cases = append(cases, g.randomTestCase(rnd, query, fmt.Sprintf("generated:%d#%d", seed, len(cases)+1)))
+	}
+	return cases
+}
+
+// KindBreakdown tallies cases by TestCase.Kind(), e.g. {"metric": 500,
+// "log": 500}, so a caller can check a Generate call produced the mix of
+// query kinds it wanted.
+func KindBreakdown(cases []TestCase) map[string]int {
+	breakdown := make(map[string]int)
+	for _, tc := range cases {
+		breakdown[tc.Kind()]++
+	}
+	return breakdown
+}
+
+func (g *Generator) randomTestCase(rnd *rand.Rand, query, source string) TestCase {
+	rang := generatorRanges[rnd.Intn(len(generatorRanges))]
+	lookback, err := time.ParseDuration(rang)
+	if err != nil {
+		lookback = 5 * time.Minute
+	}
+	// Spread windows out over the last 24h so repeated cases don't all
+	// land on the same instant.
+	end := g.Anchor.Add(-time.Duration(rnd.Int63n(int64(24 * time.Hour))))
+	start := end.Add(-lookback * time.Duration(1+rnd.Intn(4)))
+
+	direction := logproto.FORWARD
+	if rnd.Intn(2) == 0 {
+		direction = logproto.BACKWARD
+	}
+
+	var step time.Duration
+	if expr, err := syntax.ParseExpr(query); err == nil {
+		if _, ok := expr.(syntax.SampleExpr); ok {
+			step = generatorSteps[rnd.Intn(len(generatorSteps))]
+		}
+	}
+
+	return TestCase{
+		Query:     query,
+		Start:     start,
+		End:       end,
+		Direction: direction,
+		Step:      step,
+		Source:    source,
+		QueryDesc: "generated",
+	}
+}
+
+func (g *Generator) randomQuery(rnd *rand.Rand) (string, bool) {
+	selector, ok := g.randomSelector(rnd)
+	if !ok {
+		return "", false
+	}
+
+	shapes := []func(*rand.Rand, string) (string, bool){
+		g.logFilterShape,
+		g.lineFormatShape,
+		g.labelFormatShape,
+		g.unwrapShape,
+		g.rateShape,
+		g.bytesRateShape,
+		g.sumByShape,
+		g.topkShape,
+		g.multiRangeShape,
+	}
+	return shapes[rnd.Intn(len(shapes))](rnd, selector)
+}
+
+// randomSelector builds a stream selector from 1-3 randomly chosen labels,
+// e.g. `{app="foo", env="prod"}`.
+func (g *Generator) randomSelector(rnd *rand.Rand) (string, bool) {
+	if len(g.Schema.Labels) == 0 {
+		return "", false
+	}
+
+	perm := rnd.Perm(len(g.Schema.Labels))
+	count := 1 + rnd.Intn(min(3, len(g.Schema.Labels)))
+
+	var matchers []string
+	for _, idx := range perm[:count] {
+		label := g.Schema.Labels[idx]
+		if len(label.Values) == 0 {
+			continue
+		}
+		value := label.Values[rnd.Intn(len(label.Values))]
+		matchers = append(matchers, fmt.Sprintf(`%s=%q`, label.Name, value))
+	}
+	if len(matchers) == 0 {
+		return "", false
+	}
+	return "{" + strings.Join(matchers, ", ") + "}", true
+}
+
+func (g *Generator) randomFilterTerm(rnd *rand.Rand) (string, bool) {
+	if len(g.Schema.FilterTerms) == 0 {
+		return "", false
+	}
+	return g.Schema.FilterTerms[rnd.Intn(len(g.Schema.FilterTerms))], true
+}
+
+func (g *Generator) randomUnwrapField(rnd *rand.Rand) (string, bool) {
+	if len(g.Schema.UnwrapFields) == 0 {
+		return "", false
+	}
+	return g.Schema.UnwrapFields[rnd.Intn(len(g.Schema.UnwrapFields))], true
+}
+
+func (g *Generator) logFilterShape(rnd *rand.Rand, selector string) (string, bool) {
+	term, ok := g.randomFilterTerm(rnd)
+	if !ok {
+		return "", false
+	}
+	op := "|="
+	if rnd.Intn(2) == 0 {
+		op = "!="
+	}
+	return fmt.Sprintf("%s %s %q", selector, op, term), true
+}
+
+func (g *Generator) lineFormatShape(_ *rand.Rand, selector string) (string, bool) {
+	return fmt.Sprintf(`%s | line_format "{{.msg}}"`, selector), true
+}
+
+func (g *Generator) labelFormatShape(_ *rand.Rand, selector string) (string, bool) {
+	return fmt.Sprintf(`%s | label_format short=msg`, selector), true
+}
+
+func (g *Generator) unwrapShape(rnd *rand.Rand, selector string) (string, bool) {
+	field, ok := g.randomUnwrapField(rnd)
+	if !ok {
+		return "", false
+	}
+	rang := generatorRanges[rnd.Intn(len(generatorRanges))]
+	return fmt.Sprintf("sum(rate(%s | unwrap %s [%s]))", selector, field, rang), true
+}
+
+func (g *Generator) rateShape(rnd *rand.Rand, selector string) (string, bool) {
+	rang := generatorRanges[rnd.Intn(len(generatorRanges))]
+	return fmt.Sprintf("rate(%s[%s])", selector, rang), true
+}
+
+func (g *Generator) bytesRateShape(rnd *rand.Rand, selector string) (string, bool) {
+	rang := generatorRanges[rnd.Intn(len(generatorRanges))]
+	return fmt.Sprintf("bytes_rate(%s[%s])", selector, rang), true
+}
+
+func (g *Generator) sumByShape(rnd *rand.Rand, selector string) (string, bool) {
+	if len(g.Schema.Labels) == 0 {
+		return "", false
+	}
+	label := g.Schema.Labels[rnd.Intn(len(g.Schema.Labels))]
+	rang := generatorRanges[rnd.Intn(len(generatorRanges))]
+	return fmt.Sprintf("sum by (%s) (rate(%s[%s]))", label.Name, selector, rang), true
+}
+
+func (g *Generator) topkShape(rnd *rand.Rand, selector string) (string, bool) {
+	if len(g.Schema.Labels) == 0 {
+		return "", false
+	}
+	label := g.Schema.Labels[rnd.Intn(len(g.Schema.Labels))]
+	rang := generatorRanges[rnd.Intn(len(generatorRanges))]
+	k := 1 + rnd.Intn(10)
+	return fmt.Sprintf("topk(%d, sum by (%s) (rate(%s[%s])))", k, label.Name, selector, rang), true
+}
+
+// multiRangeShape builds a query comparing the same selector over two
+// different range windows, e.g. short-term vs long-term rate.
+func (g *Generator) multiRangeShape(rnd *rand.Rand, selector string) (string, bool) {
+	shortRange := generatorRanges[rnd.Intn(len(generatorRanges))]
+	longRange := generatorRanges[rnd.Intn(len(generatorRanges))]
+	return fmt.Sprintf("sum(count_over_time(%s[%s])) / sum(count_over_time(%s[%s]))", selector, shortRange, selector, longRange), true
+}
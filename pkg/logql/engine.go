@@ -9,10 +9,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/grafana/loki/v3/pkg/logqlmodel/metadata"
 	"github.com/grafana/loki/v3/pkg/tracing"
@@ -62,9 +65,77 @@ var (
 		Help:      "Count of queries blocked by per-tenant policy",
 	}, []string{"user"})
 
+	QueryPeakSamples = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "logql",
+		Name:      "query_peak_samples",
+		Help:      "The peak number of samples materialized by a single LogQL query while evaluating.",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 6),
+	})
+
+	optimizerPassDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "logql",
+		Name:      "optimizer_pass_duration_seconds",
+		Help:      "Time spent running each logical optimizer pass against a query's SampleExpr.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"pass"})
+
+	// SelectorBytesScanned is meant to track bytes scanned attributed to an
+	// individual LogQL sub-selector, labeled by tenant, keyed by
+	// selectorStrings. Nothing increments it yet: attributing scanned bytes
+	// per selector requires a wrapper around Querier.SelectLogs/SelectSamples
+	// that isn't part of this source snapshot (pkg/logql here is just
+	// engine.go). This metric and selectorStrings are groundwork for that
+	// wrapper, not a working per-selector cost accounting feature.
+	SelectorBytesScanned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "logql",
+		Name:      "selector_bytes_scanned",
+		Help:      "Total bytes scanned per LogQL sub-selector, labeled by tenant.",
+	}, []string{"tenant"})
+
 	lastEntryMinTime = time.Unix(-100, 0)
 )
 
+// nearestLookAhead bounds how many entries readStreamsNearest buffers while
+// waiting for an entry to reach the next expected tick, so a long run of
+// entries that never quite reaches the tick can't grow the buffer
+// unbounded. A full buffer is flushed against the tick as if it had been
+// reached.
+const nearestLookAhead = 8
+
+// IntervalMode selects how readStreams samples entries at a fixed interval
+// stride, as set by a query's `interval` parameter.
+type IntervalMode string
+
+const (
+	// IntervalModeStep is the default: readStreams only emits an entry once
+	// its timestamp reaches or crosses lastEntry ± interval. On bursty
+	// ingestion this can skip large gaps -- the next emitted entry is
+	// whatever happens to land after the step -- and can silently drop
+	// evenly-spaced samples a user expects at each tick.
+	IntervalModeStep IntervalMode = "step"
+	// IntervalModeNearest picks, for each expected tick, whichever of the
+	// entries leading up to it and the first entry to reach it lies closest
+	// to it (at most nearestLookAhead entries are considered, see there),
+	// instead of just the first entry to cross the tick.
+	IntervalModeNearest IntervalMode = "nearest"
+	// IntervalModeBucketed aligns ticks to interval-aligned wall-clock
+	// boundaries (e.g. every 30s on the minute) rather than to whichever
+	// entry happens to start the scan, so repeated queries against the same
+	// window produce stable sample points.
+	IntervalModeBucketed IntervalMode = "bucketed"
+)
+
+// intervalModeParams is implemented by a Params that carries an explicit
+// interval_mode (see IntervalMode) alongside the plain Interval() duration.
+// Params itself isn't part of this source snapshot (pkg/logql here is just
+// engine.go), so Eval type-asserts for it and falls back to
+// IntervalModeStep when absent; wiring IntervalMode into
+// logproto.QueryRequest, Params and the HTTP query parser's interval_mode
+// field is the remaining, undone half of this request.
+type intervalModeParams interface {
+	IntervalMode() IntervalMode
+}
+
 type QueryParams interface {
 	LogSelector() (syntax.LogSelectorExpr, error)
 	GetStart() time.Time
@@ -171,6 +242,20 @@ type EngineOpts struct {
 	// CataloguePath is the path to the catalogue in the object store.
 	CataloguePath string `yaml:"-" doc:"hidden" category:"experimental"`
 
+	// MaxSamples is the default maximum number of samples a single query may
+	// materialize while evaluating a SampleExpr. It is used as a fallback
+	// when no per-tenant limit is configured via Limits.MaxQuerySamples; a
+	// configured per-tenant limit replaces it, even a larger one. A value of
+	// 0 disables this enforcement.
+	MaxSamples int `yaml:"max_samples" category:"experimental"`
+
+	// LogicalOptimizers is the ordered pipeline of LogicalOptimizer passes
+	// run against a query's SampleExpr before evaluation. A nil slice (the
+	// default) falls back to defaultLogicalOptimizers(); use
+	// WithNoOptimizers to disable optimization entirely, e.g. to compare
+	// plans in tests and benchmarks.
+	LogicalOptimizers []LogicalOptimizer `yaml:"-"`
+
 	// DataobjScanPageCacheSize determines how many bytes of future page data
 	// should be downloaded before it's immediately needed. Used to reduce the
 	// number of roundtrips to object storage. Setting to zero disables
@@ -188,6 +273,7 @@ func (opts *EngineOpts) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet)
 	f.BoolVar(&opts.EnableV2Engine, prefix+"enable-v2-engine", false, "Experimental: Enable next generation query engine for supported queries.")
 	f.IntVar(&opts.BatchSize, prefix+"batch-size", 100, "Experimental: Batch size of the next generation query engine.")
 	f.StringVar(&opts.CataloguePath, prefix+"catalogue-path", "", "The path to the catalogue in the object store.")
+	f.IntVar(&opts.MaxSamples, prefix+"max-samples", 0, "Experimental: The default maximum number of samples a single query may materialize while evaluating, used as a fallback when no per-tenant limit is configured. 0 disables this limit.")
 	f.Var(&opts.DataobjScanPageCacheSize, prefix+"dataobjscan-page-cache-size", "Experimental: Maximum total size of future pages for DataObjScan to download before they are needed, for roundtrip reduction to object storage. Setting to zero disables downloading future pages. Only used in the next generation query engine.")
 
 	// Log executing query by default
@@ -198,6 +284,47 @@ func (opts *EngineOpts) applyDefault() {
 	if opts.MaxLookBackPeriod == 0 {
 		opts.MaxLookBackPeriod = 30 * time.Second
 	}
+	if opts.LogicalOptimizers == nil {
+		opts.LogicalOptimizers = defaultLogicalOptimizers()
+	}
+}
+
+// WithNoOptimizers returns a copy of opts with logical optimization
+// disabled, for tests and benchmarks that need to compare an unoptimized
+// plan against the default pipeline.
+func WithNoOptimizers(opts EngineOpts) EngineOpts {
+	opts.LogicalOptimizers = []LogicalOptimizer{}
+	return opts
+}
+
+// LogicalOptimizer rewrites a query's logical plan before evaluation,
+// modeled after promql-engine's logicalOptimizers. Optimize must return an
+// equivalent expression -- one that evaluates to the same result -- or an
+// error if the rewrite isn't applicable.
+type LogicalOptimizer interface {
+	// Name identifies the pass for the optimizer_pass_duration_seconds
+	// metric and the plan attached to the trace span.
+	Name() string
+	Optimize(expr syntax.SampleExpr, params Params) (syntax.SampleExpr, error)
+}
+
+// defaultLogicalOptimizers returns the engine's built-in optimizer
+// pipeline, run in order.
+func defaultLogicalOptimizers() []LogicalOptimizer {
+	return []LogicalOptimizer{
+		legacyOptimizer{},
+	}
+}
+
+// legacyOptimizer wraps the engine's original, single-pass
+// optimizeSampleExpr so it keeps running as the first stage of the
+// pluggable pipeline introduced alongside it.
+type legacyOptimizer struct{}
+
+func (legacyOptimizer) Name() string { return "legacy" }
+
+func (legacyOptimizer) Optimize(expr syntax.SampleExpr, _ Params) (syntax.SampleExpr, error) {
+	return optimizeSampleExpr(expr)
 }
 
 // QueryEngine is the LogQL engine.
@@ -231,6 +358,8 @@ func (qe *QueryEngine) Query(params Params) Query {
 		record:       true,
 		logExecQuery: qe.opts.LogExecutingQuery,
 		limits:       qe.limits,
+		maxSamples:   qe.opts.MaxSamples,
+		optimizers:   qe.opts.LogicalOptimizers,
 	}
 }
 
@@ -247,6 +376,48 @@ type query struct {
 	evaluator    EvaluatorFactory
 	record       bool
 	logExecQuery bool
+
+	// maxSamples is the engine-wide fallback sample budget, used when no
+	// per-tenant limit is configured. sampleCount and peakSamples
+	// track this query's running and highest-seen materialized sample
+	// counts and are safe for concurrent use by step evaluators.
+	maxSamples  int
+	sampleCount atomic.Int64
+	peakSamples atomic.Int64
+
+	// optimizers is the LogicalOptimizer pipeline run against a SampleExpr
+	// before evaluation; see EngineOpts.LogicalOptimizers.
+	optimizers []LogicalOptimizer
+
+	// streamSink, if set via WithStreamSink, additionally receives every
+	// entry matched by a log-selector query as readStreams produces it,
+	// rather than only once the full result is assembled.
+	streamSink StreamingResultWriter
+}
+
+// StreamingResultWriter receives each matched log entry as it is produced,
+// tagged with the label string of the stream it belongs to, rather than
+// waiting for the full logqlmodel.Streams result to be assembled. Passing
+// one to WithStreamSink lets a caller -- e.g. an HTTP response encoder --
+// start emitting output before the underlying iterator is drained.
+type StreamingResultWriter interface {
+	WriteEntry(streamLabels string, entry logproto.Entry) error
+}
+
+// WithStreamSink wires sink into q, if q was produced by QueryEngine.Query.
+// Exec still returns the same logqlmodel.Result it would without a sink,
+// but for a log-selector query sink additionally receives every entry as
+// soon as it passes readStreams' interval/direction filtering.
+//
+// Wiring this into an HTTP response encoder that flushes JSON chunks as
+// entries arrive is left to the caller: that encoder lives in the
+// query-range HTTP handler package, which isn't part of this source
+// snapshot.
+func WithStreamSink(q Query, sink StreamingResultWriter) Query {
+	if qq, ok := q.(*query); ok {
+		qq.streamSink = sink
+	}
+	return q
 }
 
 func (q *query) resultLength(res promql_parser.Value) int {
@@ -301,6 +472,7 @@ func (q *query) Exec(ctx context.Context) (logqlmodel.Result, error) {
 	rangeType := GetRangeType(q.params)
 	timer := prometheus.NewTimer(QueryTime.WithLabelValues(string(rangeType)))
 	defer timer.ObserveDuration()
+	defer func() { QueryPeakSamples.Observe(float64(q.peakSamples.Load())) }()
 
 	// records query statistics
 	start := time.Now()
@@ -373,7 +545,11 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 		}
 
 		defer util.LogErrorWithContext(ctx, "closing iterator", itr.Close)
-		streams, err := readStreams(itr, q.params.Limit(), q.params.Direction(), q.params.Interval())
+		mode := IntervalModeStep
+		if im, ok := q.params.(intervalModeParams); ok {
+			mode = im.IntervalMode()
+		}
+		streams, err := readStreams(ctx, itr, q.params.Limit(), q.params.Direction(), q.params.Interval(), mode, q.addSamples, q.streamSink)
 		return streams, err
 	default:
 		return nil, fmt.Errorf("unexpected type (%T): cannot evaluate", e)
@@ -393,6 +569,78 @@ func (q *query) checkBlocked(ctx context.Context, tenants []string) bool {
 	return false
 }
 
+// addSamples records n additional materialized samples against this query's
+// running sample budget, updates the query's peak sample count, and returns
+// logqlmodel.ErrTooManySamples if doing so pushes the total past the
+// configured limit. It is safe to call concurrently from multiple step
+// evaluators.
+func (q *query) addSamples(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	total := q.sampleCount.Add(int64(n))
+	stats.FromContext(ctx).AddSamples(int64(n))
+
+	for {
+		peak := q.peakSamples.Load()
+		if total <= peak || q.peakSamples.CompareAndSwap(peak, total) {
+			break
+		}
+	}
+
+	if maxSamples := q.maxSamplesLimit(ctx); maxSamples > 0 && total > int64(maxSamples) {
+		return logqlmodel.ErrTooManySamples
+	}
+	return nil
+}
+
+// dropSamples backs out n previously counted samples from this query's
+// running sample budget, used when series counted earlier are later
+// discarded, e.g. a multi-variant branch dropped for exceeding maxSeries.
+func (q *query) dropSamples(n int) {
+	if n <= 0 {
+		return
+	}
+	q.sampleCount.Add(-int64(n))
+}
+
+// maxSamplesLimit resolves the sample budget for this query: the smallest
+// positive per-tenant limit if one is configured, falling back to the
+// engine-wide EngineOpts.MaxSamples default. A result of 0 means unlimited.
+func (q *query) maxSamplesLimit(ctx context.Context) int {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return q.maxSamples
+	}
+
+	maxSamplesCapture := func(id string) int { return q.limits.MaxQuerySamples(ctx, id) }
+	if maxSamples := validation.SmallestPositiveIntPerTenant(tenantIDs, maxSamplesCapture); maxSamples > 0 {
+		return maxSamples
+	}
+	return q.maxSamples
+}
+
+// runLogicalOptimizers runs the engine's configured LogicalOptimizer
+// pipeline against expr in order, recording each pass's duration and
+// attaching the resulting plan to the current trace span.
+func (q *query) runLogicalOptimizers(ctx context.Context, expr syntax.SampleExpr) (syntax.SampleExpr, error) {
+	sp := trace.SpanFromContext(ctx)
+
+	var err error
+	for _, opt := range q.optimizers {
+		start := time.Now()
+		expr, err = opt.Optimize(expr, q.params)
+		optimizerPassDuration.WithLabelValues(opt.Name()).Observe(time.Since(start).Seconds())
+		if err != nil {
+			return nil, fmt.Errorf("optimizer pass %q: %w", opt.Name(), err)
+		}
+	}
+
+	sp.SetAttributes(attribute.String("plan", expr.String()))
+	return expr, nil
+}
+
 // evalSample evaluate a sampleExpr
 func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_parser.Value, error) {
 	if lit, ok := expr.(*syntax.LiteralExpr); ok {
@@ -416,7 +664,7 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 		}
 	}
 
-	expr, err = optimizeSampleExpr(expr)
+	expr, err = q.runLogicalOptimizers(ctx, expr)
 	if err != nil {
 		return nil, err
 	}
@@ -493,8 +741,13 @@ func vectorsToSeriesWithLimit(vec promql.Vector, sm map[uint64]promql.Series, ma
 	return limitExceeded
 }
 
-func multiVariantVectorsToSeries(ctx context.Context, maxSeries int, vec promql.Vector, sm map[string]map[uint64]promql.Series, skippedVariants map[string]struct{}) int {
+// multiVariantVectorsToSeries folds vec into sm, grouped by variant, and
+// returns the net change in series count and in raw sample (point) count.
+// Both can be negative, as dropping a variant that exceeded maxSeries backs
+// out everything counted for it in earlier iterations.
+func multiVariantVectorsToSeries(ctx context.Context, maxSeries int, vec promql.Vector, sm map[string]map[uint64]promql.Series, skippedVariants map[string]struct{}) (int, int) {
 	count := 0
+	samples := 0
 	metadataCtx := metadata.FromContext(ctx)
 
 	for _, p := range vec {
@@ -524,6 +777,7 @@ func multiVariantVectorsToSeries(ctx context.Context, maxSeries int, vec promql.
 			// This can cause count to be negative, as we may be removing series added in a previous iteration
 			// However, since we sum this value across all iterations, a negative will make sure the total series count is correct
 			count = count - len(sm[variantLabel])
+			samples -= samplesIn(sm[variantLabel])
 			delete(sm, variantLabel)
 			metadataCtx.AddWarning(fmt.Sprintf("maximum of series (%d) reached for variant (%s)", maxSeries, variantLabel))
 			continue
@@ -544,9 +798,21 @@ func multiVariantVectorsToSeries(ctx context.Context, maxSeries int, vec promql.
 			F: p.F,
 		})
 		sm[variantLabel][hash] = series
+		samples++
 	}
 
-	return count
+	return count, samples
+}
+
+// samplesIn returns the total number of points already accumulated across a
+// variant's series, used by multiVariantVectorsToSeries to back out the
+// sample budget when the variant is dropped for exceeding maxSeries.
+func samplesIn(series map[uint64]promql.Series) int {
+	n := 0
+	for _, s := range series {
+		n += len(s.Floats)
+	}
+	return n
 }
 
 func (q *query) JoinSampleVector(ctx context.Context, next bool, r StepResult, stepEvaluator StepEvaluator, maxSeries int, mergeFirstLast bool) (promql_parser.Value, error) {
@@ -571,6 +837,10 @@ func (q *query) JoinSampleVector(ctx context.Context, next bool, r StepResult, s
 	}
 
 	if GetRangeType(q.params) == InstantType {
+		if err := q.addSamples(ctx, len(vec)); err != nil {
+			return nil, err
+		}
+
 		// an instant query sharded first/last_over_time can return a single vector
 		if mergeFirstLast {
 			vectorsToSeries(vec, seriesIndex)
@@ -595,6 +865,9 @@ func (q *query) JoinSampleVector(ctx context.Context, next bool, r StepResult, s
 
 	for next {
 		vec = r.SampleVector()
+		if err := q.addSamples(ctx, len(vec)); err != nil {
+			return nil, err
+		}
 
 		if httpreq.IsLogsDrilldownRequest(ctx) {
 			// For Logs Drilldown requests, use limited vectorsToSeries to prevent exceeding maxSeries
@@ -628,6 +901,106 @@ func (q *query) JoinSampleVector(ctx context.Context, next bool, r StepResult, s
 	return result, stepEvaluator.Error()
 }
 
+// MatrixResult adapts a promql.Matrix step result -- e.g. produced by a
+// range/subquery branch of a variants() query such as the
+// bytes_over_time(...) in variants(rate(...), bytes_over_time(...), ...) --
+// to the StepResult contract the rest of this file's Join functions expect.
+// Its series are expected to already carry constants.VariantLabel, the same
+// way JoinMultiVariantSampleVector expects of a SampleVector.
+type MatrixResult struct {
+	Matrix promql.Matrix
+}
+
+func (r MatrixResult) SampleVector() promql.Vector {
+	vec := make(promql.Vector, 0, len(r.Matrix))
+	for _, series := range r.Matrix {
+		for _, p := range series.Floats {
+			vec = append(vec, promql.Sample{Metric: series.Metric, T: p.T, F: p.F})
+		}
+	}
+	return vec
+}
+
+// StreamsResult adapts a logqlmodel.Streams step result -- produced by a
+// variant that is itself a bare log selector, e.g. the {app="foo"} in
+// variants(rate(...), {app="foo"}) -- to the StepResult contract. Unlike
+// SampleVector and MatrixResult, a StreamsResult can't usefully flatten into
+// a promql.Vector, so it is joined separately by joinMultiVariantStreams
+// instead of through JoinMultiVariantSampleVector.
+type StreamsResult struct {
+	Streams logqlmodel.Streams
+}
+
+func (StreamsResult) SampleVector() promql.Vector { return nil }
+
+// VariantsResult is the value evalVariants returns when the first step of
+// its step evaluator is a StreamsResult: Streams then holds the log lines
+// of every step, grouped by their labels, and Matrix is left empty. Metric
+// variants (SampleVector/MatrixResult steps) are not returned as a
+// VariantsResult; evalVariants returns them as the promql.Matrix (or, for
+// an instant query, promql.Vector) built by JoinMultiVariantSampleVector.
+// Log and metric variants are not combined in one result: after a
+// StreamsResult first step, a metric step fails the query with an
+// unsupported result type error, and after a metric first step, a
+// StreamsResult step contributes no samples.
+//
+// Encoding this across the query-range HTTP boundary as a dedicated
+// ResultTypeVariants envelope is the remaining, undone half of this
+// request -- queryrange isn't part of this source snapshot (pkg/logql here
+// is just engine.go), so nothing yet consults VariantsResult outside this
+// package.
+type VariantsResult struct {
+	Matrix  promql.Matrix
+	Streams logqlmodel.Streams
+}
+
+func (VariantsResult) Type() promql_parser.ValueType { return promql_parser.ValueTypeMatrix }
+
+func (r VariantsResult) String() string {
+	return fmt.Sprintf("variants result: %d matrix series, %d streams", len(r.Matrix), len(r.Streams))
+}
+
+// joinMultiVariantStreams drains a variants() step evaluator whose steps
+// reduce to log streams into a single VariantsResult. Each step's
+// StreamsResult is expected to already carry constants.VariantLabel on
+// every stream's label set, so streams from distinct variants naturally
+// group under their own labels once assembled by streamResultBuffer.
+func (q *query) joinMultiVariantStreams(ctx context.Context, next bool, r StreamsResult, stepEvaluator StepEvaluator) (promql_parser.Value, error) {
+	buf := newStreamResultBuffer()
+	for next {
+		for _, stream := range r.Streams {
+			for _, entry := range stream.Entries {
+				if err := q.addSamples(ctx, 1); err != nil {
+					return nil, err
+				}
+				if err := buf.add(stream.Labels, entry); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		var rr StepResult
+		next, _, rr = stepEvaluator.Next()
+		if stepEvaluator.Error() != nil {
+			return nil, stepEvaluator.Error()
+		}
+		if next {
+			sr, ok := rr.(StreamsResult)
+			if !ok {
+				return nil, fmt.Errorf("unsupported result type: %T", rr)
+			}
+			r = sr
+		}
+	}
+
+	return VariantsResult{Streams: buf.result()}, stepEvaluator.Error()
+}
+
+// JoinMultiVariantSampleVector joins a multi-variant step evaluator's steps
+// into a single promql.Matrix. It only relies on StepResult's SampleVector
+// method, so any step type that flattens to a promql.Vector tagged with
+// constants.VariantLabel -- not just SampleVector, but also MatrixResult --
+// is handled identically here.
 func (q *query) JoinMultiVariantSampleVector(ctx context.Context, next bool, r StepResult, stepEvaluator StepEvaluator, maxSeries int) (promql_parser.Value, error) {
 	vec := promql.Vector{}
 	if next {
@@ -640,7 +1013,10 @@ func (q *query) JoinMultiVariantSampleVector(ctx context.Context, next bool, r S
 	skippedVariants := map[string]struct{}{}
 
 	if GetRangeType(q.params) == InstantType {
-		multiVariantVectorsToSeries(ctx, maxSeries, vec, seriesIndex, skippedVariants)
+		_, sampleDelta := multiVariantVectorsToSeries(ctx, maxSeries, vec, seriesIndex, skippedVariants)
+		if err := q.addSamples(ctx, sampleDelta); err != nil {
+			return nil, err
+		}
 
 		// Filter the vector to remove skipped variants
 		filterVariantVector(&vec, skippedVariants)
@@ -661,7 +1037,15 @@ func (q *query) JoinMultiVariantSampleVector(ctx context.Context, next bool, r S
 		vec = r.SampleVector()
 		// Filter out any samples from variants we've already skipped
 		filterVariantVector(&vec, skippedVariants)
-		seriesCount += multiVariantVectorsToSeries(ctx, maxSeries, vec, seriesIndex, skippedVariants)
+		seriesDelta, sampleDelta := multiVariantVectorsToSeries(ctx, maxSeries, vec, seriesIndex, skippedVariants)
+		seriesCount += seriesDelta
+		if sampleDelta >= 0 {
+			if err := q.addSamples(ctx, sampleDelta); err != nil {
+				return nil, err
+			}
+		} else {
+			q.dropSamples(-sampleDelta)
+		}
 
 		next, _, r = stepEvaluator.Next()
 		if stepEvaluator.Error() != nil {
@@ -700,6 +1084,44 @@ func filterVariantVector(vec *promql.Vector, skipped map[string]struct{}) {
 	}
 }
 
+// subqueryInnerRange returns the time range a subquery of the form
+// <agg>_over_time(<inner>[range:step]) must evaluate its inner SampleExpr
+// over: [start-range, end] for range queries, or [start-range, start] for
+// instant queries, matching how promql-engine windows instant subqueries.
+//
+// Nothing calls this yet: constructing the inner StepEvaluator for a
+// subquery requires the evaluator package's step-evaluator factory, which
+// isn't part of this source snapshot (pkg/logql here is just engine.go).
+// This is groundwork for that wiring, not a working subquery implementation.
+func subqueryInnerRange(params Params, rng time.Duration) (start, end time.Time) {
+	if GetRangeType(params) == InstantType {
+		return params.Start().Add(-rng), params.Start()
+	}
+	return params.Start().Add(-rng), params.End()
+}
+
+// selectorStrings walks expr and returns the string form of every distinct
+// syntax.LogSelectorExpr it contains, e.g. each `{...}` sub-selector of a
+// binary operation combining multiple streams selectors. This is meant to
+// be the key used to attribute per-selector scan cost reported via
+// SelectorBytesScanned -- see that metric's doc comment for what's still
+// missing to make the attribution real.
+func selectorStrings(expr syntax.Expr) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	expr.Walk(func(e syntax.Expr) bool {
+		if sel, ok := e.(syntax.LogSelectorExpr); ok {
+			s := sel.String()
+			if _, ok := seen[s]; !ok {
+				seen[s] = struct{}{}
+				out = append(out, s)
+			}
+		}
+		return true
+	})
+	return out
+}
+
 func (q *query) checkIntervalLimit(expr syntax.SampleExpr, limit time.Duration) error {
 	var err error
 	expr.Walk(func(e syntax.Expr) bool {
@@ -708,12 +1130,54 @@ func (q *query) checkIntervalLimit(expr syntax.SampleExpr, limit time.Duration)
 			if e.Interval > limit {
 				err = fmt.Errorf("%w: [%s] > [%s]", logqlmodel.ErrIntervalLimit, model.Duration(e.Interval), model.Duration(limit))
 			}
+			// A StartTimestampHint only ever injects a sample that
+			// injectCounterStartZero has clamped to the window's own start,
+			// so the effective window checked above already covers it and
+			// no separate comparison against e.StartTimestampHint is needed.
 		}
 		return true
 	})
 	return err
 }
 
+// injectCounterStartZero inserts a synthetic zero-value sample at
+// startTimestampMs before samples' first real point, mirroring how OTLP
+// ingestion turns a counter's reported start time into a created-timestamp
+// sample (see logproto.SampleWithCreatedTimestamp) so that rate(), increase()
+// and resets() don't treat the first observation as an unexplained jump from
+// zero. startTimestampMs is clamped to windowStart when it would otherwise
+// fall outside the query window, and no sample is injected if one already
+// exists at or before the (clamped) start timestamp -- e.g. because a prior
+// reset within the same window already anchored it.
+//
+// samples must be sorted by TimestampMs ascending. A zero startTimestampMs
+// means the hint was absent (no "| start_timestamp=..." stage matched), in
+// which case samples is returned unchanged.
+//
+// Nothing calls this yet: producing samples for a counter-backed
+// range-vector function (rate(), increase(), resets()) happens in the
+// evaluator package's range-vector iterators, which aren't part of this
+// source snapshot (pkg/logql here is just engine.go). This is groundwork
+// for that wiring, not a working start-timestamp feature.
+func injectCounterStartZero(samples []logproto.LegacySample, startTimestampMs int64, windowStart time.Time) []logproto.LegacySample {
+	if startTimestampMs == 0 || len(samples) == 0 {
+		return samples
+	}
+
+	ts := startTimestampMs
+	if floor := windowStart.UnixMilli(); ts < floor {
+		ts = floor
+	}
+	if ts >= samples[0].TimestampMs {
+		return samples
+	}
+
+	out := make([]logproto.LegacySample, 0, len(samples)+1)
+	out = append(out, logproto.LegacySample{TimestampMs: ts, Value: 0})
+	out = append(out, samples...)
+	return out
+}
+
 func (q *query) evalLiteral(_ context.Context, expr *syntax.LiteralExpr) (promql_parser.Value, error) {
 	value, err := expr.Value()
 	if err != nil {
@@ -776,12 +1240,37 @@ func PopulateMatrixFromScalar(data promql.Scalar, params Params) promql.Matrix {
 	return promql.Matrix{series}
 }
 
-// readStreams reads the streams from the iterator and returns them sorted.
-// If categorizeLabels is true, the stream labels contains just the stream labels and entries inside each stream have their
-// structuredMetadata and parsed fields populated with structured metadata labels plus the parsed labels respectively.
-// Otherwise, the stream labels are the whole series labels including the stream labels, structured metadata labels and parsed labels.
-func readStreams(i iter.EntryIterator, size uint32, dir logproto.Direction, interval time.Duration) (logqlmodel.Streams, error) {
-	streams := map[string]*logproto.Stream{}
+// readStreamsSink receives each entry readStreamsStreaming's interval and
+// direction filtering lets through, tagged with the label string of the
+// stream it belongs to, in the order the underlying iterator produces them.
+type readStreamsSink func(streamLabels string, entry logproto.Entry) error
+
+// readStreamsStreaming applies readStreams' size/interval/direction
+// filtering to i one entry at a time and calls sink for each entry that
+// passes, instead of buffering every matched entry into an in-memory
+// map[string]*logproto.Stream before returning. This keeps the filtering
+// logic itself free of any per-stream buffering; grouping entries back into
+// logproto.Stream values, if a caller needs that, is sink's job -- see
+// readStreams, which does exactly that to preserve its existing contract.
+// addSamples, if non-nil, is called once per emitted entry to charge it
+// against the query's sample budget; a non-nil error aborts the read.
+// mode selects which of the IntervalMode strategies decides which entries
+// within each interval actually get emitted; the zero value behaves like
+// IntervalModeStep.
+func readStreamsStreaming(ctx context.Context, i iter.EntryIterator, size uint32, dir logproto.Direction, interval time.Duration, mode IntervalMode, addSamples func(context.Context, int) error, sink readStreamsSink) error {
+	switch mode {
+	case IntervalModeNearest:
+		return readStreamsNearest(ctx, i, size, dir, interval, addSamples, sink)
+	case IntervalModeBucketed:
+		return readStreamsBucketed(ctx, i, size, dir, interval, addSamples, sink)
+	default:
+		return readStreamsStep(ctx, i, size, dir, interval, addSamples, sink)
+	}
+}
+
+// readStreamsStep is the IntervalModeStep strategy: it emits an entry once
+// its timestamp reaches or crosses lastEntry ± interval.
+func readStreamsStep(ctx context.Context, i iter.EntryIterator, size uint32, dir logproto.Direction, interval time.Duration, addSamples func(context.Context, int) error, sink readStreamsSink) error {
 	respSize := uint32(0)
 	// lastEntry should be a really old time so that the first comparison is always true, we use a negative
 	// value here because many unit tests start at time.Unix(0,0)
@@ -798,25 +1287,203 @@ func readStreams(i iter.EntryIterator, size uint32, dir logproto.Direction, inte
 		// If lastEntry.Unix < 0 this is the first pass through the loop and we should output the line.
 		// Then check to see if the entry is equal to, or past a forward or reverse step
 		if interval == 0 || lastEntry.Unix() < 0 || forwardShouldOutput || backwardShouldOutput {
-			stream, ok := streams[streamLabels]
-			if !ok {
-				stream = &logproto.Stream{
-					Labels: streamLabels,
+			if err := sink(streamLabels, entry); err != nil {
+				return err
+			}
+			lastEntry = entry.Timestamp
+			respSize++
+			if addSamples != nil {
+				if err := addSamples(ctx, 1); err != nil {
+					return err
 				}
-				streams[streamLabels] = stream
 			}
-			stream.Entries = append(stream.Entries, entry)
-			lastEntry = i.At().Timestamp
+		}
+	}
+	return i.Err()
+}
+
+// readStreamsBucketed is the IntervalModeBucketed strategy: rather than
+// stepping from whichever entry happens to start the scan, it truncates
+// each entry's timestamp down to the enclosing interval-aligned wall-clock
+// boundary and emits the first entry seen in each distinct bucket. Because
+// the boundaries are absolute, not relative to the scan's first match,
+// repeated queries over the same window land on the same sample points.
+func readStreamsBucketed(ctx context.Context, i iter.EntryIterator, size uint32, _ logproto.Direction, interval time.Duration, addSamples func(context.Context, int) error, sink readStreamsSink) error {
+	respSize := uint32(0)
+	var lastBucket time.Time
+	haveBucket := false
+
+	for respSize < size && i.Next() {
+		streamLabels, entry := i.Labels(), i.At()
+
+		bucket := entry.Timestamp.Truncate(interval)
+		if interval == 0 || !haveBucket || !bucket.Equal(lastBucket) {
+			if err := sink(streamLabels, entry); err != nil {
+				return err
+			}
+			lastBucket = bucket
+			haveBucket = true
 			respSize++
+			if addSamples != nil {
+				if err := addSamples(ctx, 1); err != nil {
+					return err
+				}
+			}
 		}
 	}
+	return i.Err()
+}
 
-	result := make(logqlmodel.Streams, 0, len(streams))
-	for _, stream := range streams {
-		result = append(result, *stream)
+// readStreamsNearest is the IntervalModeNearest strategy: instead of
+// emitting the first entry that reaches or crosses each expected tick
+// (lastEntry ± interval), it buffers the entries that arrive before the
+// tick together with the first one that reaches or crosses it, up to
+// nearestLookAhead entries, and emits whichever one lands closest to the
+// tick, so an entry just short of a tick can win over one that lands well
+// past it. The next tick is measured from the tick itself, not from the
+// emitted entry's timestamp.
+func readStreamsNearest(ctx context.Context, i iter.EntryIterator, size uint32, dir logproto.Direction, interval time.Duration, addSamples func(context.Context, int) error, sink readStreamsSink) error {
+	if interval == 0 {
+		return readStreamsStep(ctx, i, size, dir, interval, addSamples, sink)
+	}
+
+	type candidate struct {
+		labels string
+		entry  logproto.Entry
+	}
+
+	respSize := uint32(0)
+	lastEntry := lastEntryMinTime
+	first := true
+	var buf []candidate
+
+	tick := func() time.Time {
+		if dir == logproto.BACKWARD {
+			return lastEntry.Add(-interval)
+		}
+		return lastEntry.Add(interval)
+	}
+
+	flush := func(target time.Time) error {
+		if len(buf) == 0 {
+			return nil
+		}
+		best := buf[0]
+		bestDist := best.entry.Timestamp.Sub(target).Abs()
+		for _, c := range buf[1:] {
+			if d := c.entry.Timestamp.Sub(target).Abs(); d < bestDist {
+				best, bestDist = c, d
+			}
+		}
+		if err := sink(best.labels, best.entry); err != nil {
+			return err
+		}
+		lastEntry = target
+		respSize++
+		if addSamples != nil {
+			if err := addSamples(ctx, 1); err != nil {
+				return err
+			}
+		}
+		buf = buf[:0]
+		return nil
+	}
+
+	for respSize < size && i.Next() {
+		streamLabels, entry := i.Labels(), i.At()
+
+		if first {
+			if err := sink(streamLabels, entry); err != nil {
+				return err
+			}
+			lastEntry = entry.Timestamp
+			first = false
+			respSize++
+			if addSamples != nil {
+				if err := addSamples(ctx, 1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		target := tick()
+		reachedTick := (dir == logproto.FORWARD && (entry.Timestamp.Equal(target) || entry.Timestamp.After(target))) ||
+			(dir == logproto.BACKWARD && (entry.Timestamp.Equal(target) || entry.Timestamp.Before(target)))
+
+		buf = append(buf, candidate{labels: streamLabels, entry: entry})
+
+		if reachedTick || len(buf) >= nearestLookAhead {
+			if err := flush(target); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(tick()); err != nil {
+		return err
+	}
+	return i.Err()
+}
+
+// streamResultBuffer groups the entries readStreamsStreaming hands it back
+// into the map[string]*logproto.Stream shape readStreams has always
+// returned. readStreams' contract requires a fully materialized, sorted
+// result, so this buffering is unavoidable for that call path; callers that
+// don't need the whole result in memory should use readStreamsStreaming
+// directly with their own sink instead.
+type streamResultBuffer struct {
+	streams map[string]*logproto.Stream
+	order   []string
+}
+
+func newStreamResultBuffer() *streamResultBuffer {
+	return &streamResultBuffer{streams: map[string]*logproto.Stream{}}
+}
+
+func (b *streamResultBuffer) add(streamLabels string, entry logproto.Entry) error {
+	stream, ok := b.streams[streamLabels]
+	if !ok {
+		stream = &logproto.Stream{Labels: streamLabels}
+		b.streams[streamLabels] = stream
+		b.order = append(b.order, streamLabels)
+	}
+	stream.Entries = append(stream.Entries, entry)
+	return nil
+}
+
+func (b *streamResultBuffer) result() logqlmodel.Streams {
+	result := make(logqlmodel.Streams, 0, len(b.streams))
+	for _, label := range b.order {
+		result = append(result, *b.streams[label])
 	}
 	sort.Sort(result)
-	return result, i.Err()
+	return result
+}
+
+// readStreams reads the streams from the iterator and returns them sorted.
+// If categorizeLabels is true, the stream labels contains just the stream labels and entries inside each stream have their
+// structuredMetadata and parsed fields populated with structured metadata labels plus the parsed labels respectively.
+// Otherwise, the stream labels are the whole series labels including the stream labels, structured metadata labels and parsed labels.
+// addSamples, if non-nil, is called once per emitted entry to charge it
+// against the query's sample budget; a non-nil error aborts the read.
+// sink, if non-nil, additionally receives each emitted entry as it is
+// produced, before the full result is assembled -- see WithStreamSink.
+// mode selects which IntervalMode strategy decides which entries within
+// each interval actually get emitted.
+func readStreams(ctx context.Context, i iter.EntryIterator, size uint32, dir logproto.Direction, interval time.Duration, mode IntervalMode, addSamples func(context.Context, int) error, sink StreamingResultWriter) (logqlmodel.Streams, error) {
+	buf := newStreamResultBuffer()
+	err := readStreamsStreaming(ctx, i, size, dir, interval, mode, addSamples, func(streamLabels string, entry logproto.Entry) error {
+		if sink != nil {
+			if err := sink.WriteEntry(streamLabels, entry); err != nil {
+				return err
+			}
+		}
+		return buf.add(streamLabels, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.result(), nil
 }
 
 type groupedAggregation struct {
@@ -828,6 +1495,74 @@ type groupedAggregation struct {
 	reverseHeap vectorByReverseValueHeap
 }
 
+// AggregationParams carries one aggregation's grouping configuration and
+// numeric parameter (e.g. k for topk/bottomk, the quantile for a
+// quantile-over-sketch reducer) to a registered AggregatorFactory.
+type AggregationParams struct {
+	// Grouping lists the grouping labels of a `by`/`without` clause; Without
+	// reports which of the two it is. Both are empty for an unqualified
+	// aggregation.
+	Grouping []string
+	Without  bool
+	// Param is the reducer's numeric argument, e.g. `topk(<Param>, ...)`.
+	// Zero if the aggregation takes none.
+	Param float64
+}
+
+// Aggregator reduces the samples sharing one group key into that group's
+// output sample(s), the same role groupedAggregation plays for the
+// built-in topk/bottomk/avg/sum/... operators.
+type Aggregator interface {
+	// Add folds one input sample's value, under the given output labels,
+	// into the aggregation.
+	Add(lbls labels.Labels, value float64)
+	// Result returns the group's output sample(s) -- more than one for a
+	// multi-output reducer like topk/bottomk, exactly one otherwise.
+	Result() []promql.Sample
+	// Reset clears the Aggregator so the same instance can be reused for
+	// the next step rather than reallocated.
+	Reset()
+}
+
+// AggregatorFactory builds a fresh Aggregator for one group key, configured
+// by params.
+type AggregatorFactory func(params AggregationParams) Aggregator
+
+var (
+	aggregationRegistryMu sync.RWMutex
+	aggregationRegistry   = map[string]AggregatorFactory{}
+)
+
+// RegisterAggregation registers factory under name, so that an aggregation
+// expression naming it is meant to route through factory's Aggregator
+// instead of one of the built-in topk/bottomk/avg/sum/... operators.
+// Registering the same name twice replaces the previous factory. Typically
+// called from an init() in a package that wants to add a custom reducer
+// (e.g. approx_count_distinct over a HyperLogLog sketch) without forking
+// this engine.
+//
+// The aggregation-operator grammar lives in the syntax package and the
+// op-enum dispatch loop that groupedAggregation backs lives in the
+// evaluator -- neither is part of this source snapshot (pkg/logql here is
+// just engine.go), so nothing yet consults this registry: this is
+// groundwork for that wiring, not a working custom-reducer feature. Parsing
+// a registered name and routing it through lookupAggregation instead of the
+// op enum is the remaining, undone half of this request.
+func RegisterAggregation(name string, factory AggregatorFactory) {
+	aggregationRegistryMu.Lock()
+	defer aggregationRegistryMu.Unlock()
+	aggregationRegistry[name] = factory
+}
+
+// lookupAggregation returns the AggregatorFactory registered under name, if
+// any.
+func lookupAggregation(name string) (AggregatorFactory, bool) {
+	aggregationRegistryMu.RLock()
+	defer aggregationRegistryMu.RUnlock()
+	factory, ok := aggregationRegistry[name]
+	return factory, ok
+}
+
 func (q *query) evalVariants(
 	ctx context.Context,
 	expr syntax.VariantsExpr,
@@ -849,7 +1584,7 @@ func (q *query) evalVariants(
 				return nil, err
 			}
 
-			vExpr, err := optimizeSampleExpr(v)
+			vExpr, err := q.runLogicalOptimizers(ctx, v)
 			if err != nil {
 				return nil, err
 			}
@@ -877,6 +1612,12 @@ func (q *query) evalVariants(
 			maxSeriesCapture := func(id string) int { return q.limits.MaxQuerySeries(ctx, id) }
 			maxSeries := validation.SmallestPositiveIntPerTenant(tenantIDs, maxSeriesCapture)
 			return q.JoinMultiVariantSampleVector(ctx, next, vec, stepEvaluator, maxSeries)
+		case MatrixResult:
+			maxSeriesCapture := func(id string) int { return q.limits.MaxQuerySeries(ctx, id) }
+			maxSeries := validation.SmallestPositiveIntPerTenant(tenantIDs, maxSeriesCapture)
+			return q.JoinMultiVariantSampleVector(ctx, next, vec, stepEvaluator, maxSeries)
+		case StreamsResult:
+			return q.joinMultiVariantStreams(ctx, next, vec, stepEvaluator)
 		default:
 			return nil, fmt.Errorf("unsupported result type: %T", r)
 		}
@@ -0,0 +1,280 @@
+package logqltest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// loadCmd loads a fixed interval's worth of streams via a sequence of
+// pushCmds, e.g.:
+//
+//	load 15s
+//	  push {job="api"} 0 hello
+//	  push {job="api"} 15s world
+type loadCmd struct {
+	interval time.Duration
+	pushes   []pushCmd
+}
+
+// pushCmd adds a single log line to a stream at an offset from the test's
+// base time.
+type pushCmd struct {
+	labels labels.Labels
+	offset time.Duration
+	line   string
+}
+
+// evalCmd evaluates a single LogQL query and checks its result against a
+// list of expected samples, e.g.:
+//
+//	eval instant at 1m count_over_time({job="api"}[1m])
+//	  {job="api"} 4 @ 60
+type evalCmd struct {
+	instant  bool
+	at       time.Duration
+	step     time.Duration
+	query    string
+	expected []expectedSample
+}
+
+// expectedSample is one `{labels} value @ time` line following an eval
+// command.
+type expectedSample struct {
+	labels labels.Labels
+	value  float64
+	at     time.Duration
+}
+
+// parseScript splits a test script into its load/eval commands.
+func parseScript(input string) ([]interface{}, error) {
+	lines := strings.Split(input, "\n")
+	var cmds []interface{}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("line %d: unexpected indented line %q outside of a block", i+1, line)
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "load "):
+			cmd, consumed, err := parseLoad(trimmed, lines[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			cmds = append(cmds, cmd)
+			i += consumed
+
+		case strings.HasPrefix(trimmed, "eval "):
+			cmd, consumed, err := parseEval(trimmed, lines[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			cmds = append(cmds, cmd)
+			i += consumed
+
+		default:
+			return nil, fmt.Errorf("line %d: unrecognized command %q", i+1, trimmed)
+		}
+	}
+
+	return cmds, nil
+}
+
+func parseLoad(header string, rest []string) (loadCmd, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) != 2 {
+		return loadCmd{}, 0, fmt.Errorf("expected 'load <interval>', got %q", header)
+	}
+	interval, err := time.ParseDuration(fields[1])
+	if err != nil {
+		return loadCmd{}, 0, fmt.Errorf("invalid load interval %q: %w", fields[1], err)
+	}
+
+	cmd := loadCmd{interval: interval}
+	consumed := 0
+	for _, raw := range rest {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			break
+		}
+		if !strings.HasPrefix(raw, " ") && !strings.HasPrefix(raw, "\t") {
+			break
+		}
+
+		push, err := parsePush(trimmed)
+		if err != nil {
+			return loadCmd{}, 0, err
+		}
+		cmd.pushes = append(cmd.pushes, push)
+		consumed++
+	}
+	return cmd, consumed, nil
+}
+
+func parsePush(line string) (pushCmd, error) {
+	if !strings.HasPrefix(line, "push ") {
+		return pushCmd{}, fmt.Errorf("expected 'push {labels} <offset> <line>', got %q", line)
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(line, "push "))
+
+	end := strings.Index(rest, "}")
+	if !strings.HasPrefix(rest, "{") || end < 0 {
+		return pushCmd{}, fmt.Errorf("expected '{labels}' in %q", line)
+	}
+	lbls, err := parseLabelSet(rest[:end+1])
+	if err != nil {
+		return pushCmd{}, err
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(rest[end+1:]), " ", 2)
+	if len(fields) != 2 {
+		return pushCmd{}, fmt.Errorf("expected '<offset> <line>' after labels in %q", line)
+	}
+	offset, err := parseTimeOffset(fields[0])
+	if err != nil {
+		return pushCmd{}, err
+	}
+
+	return pushCmd{labels: lbls, offset: offset, line: fields[1]}, nil
+}
+
+func parseEval(header string, rest []string) (evalCmd, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return evalCmd{}, 0, fmt.Errorf("expected 'eval (instant|range) at <time> [step <d>] <query>', got %q", header)
+	}
+	if fields[0] != "eval" {
+		return evalCmd{}, 0, fmt.Errorf("expected 'eval', got %q", fields[0])
+	}
+
+	cmd := evalCmd{}
+	switch fields[1] {
+	case "instant":
+		cmd.instant = true
+	case "range":
+		cmd.instant = false
+	default:
+		return evalCmd{}, 0, fmt.Errorf("expected 'instant' or 'range', got %q", fields[1])
+	}
+
+	if fields[2] != "at" {
+		return evalCmd{}, 0, fmt.Errorf("expected 'at', got %q", fields[2])
+	}
+	if len(fields) < 4 {
+		return evalCmd{}, 0, fmt.Errorf("missing time after 'at'")
+	}
+	at, err := parseTimeOffset(fields[3])
+	if err != nil {
+		return evalCmd{}, 0, err
+	}
+	cmd.at = at
+
+	queryFields := fields[4:]
+	if len(queryFields) >= 2 && queryFields[0] == "step" {
+		step, err := time.ParseDuration(queryFields[1])
+		if err != nil {
+			return evalCmd{}, 0, fmt.Errorf("invalid step %q: %w", queryFields[1], err)
+		}
+		cmd.step = step
+		queryFields = queryFields[2:]
+	}
+	if len(queryFields) == 0 {
+		return evalCmd{}, 0, fmt.Errorf("missing query in eval command")
+	}
+	cmd.query = strings.Join(queryFields, " ")
+
+	consumed := 0
+	for _, raw := range rest {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			break
+		}
+		if !strings.HasPrefix(raw, " ") && !strings.HasPrefix(raw, "\t") {
+			break
+		}
+
+		sample, err := parseExpectedSample(trimmed)
+		if err != nil {
+			return evalCmd{}, 0, err
+		}
+		cmd.expected = append(cmd.expected, sample)
+		consumed++
+	}
+	return cmd, consumed, nil
+}
+
+func parseExpectedSample(line string) (expectedSample, error) {
+	end := strings.Index(line, "}")
+	if !strings.HasPrefix(line, "{") || end < 0 {
+		return expectedSample{}, fmt.Errorf("expected '{labels} value @ time', got %q", line)
+	}
+	lbls, err := parseLabelSet(line[:end+1])
+	if err != nil {
+		return expectedSample{}, err
+	}
+
+	fields := strings.Fields(line[end+1:])
+	if len(fields) != 3 || fields[1] != "@" {
+		return expectedSample{}, fmt.Errorf("expected 'value @ time' after labels, got %q", line[end+1:])
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return expectedSample{}, fmt.Errorf("invalid expected value %q: %w", fields[0], err)
+	}
+	at, err := parseTimeOffset(fields[2])
+	if err != nil {
+		return expectedSample{}, err
+	}
+
+	return expectedSample{labels: lbls, value: value, at: at}, nil
+}
+
+// parseTimeOffset parses either a bare integer number of seconds (as
+// promqltest does) or a Go duration string like "90s".
+func parseTimeOffset(s string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// parseLabelSet parses a minimal "{name=\"value\", ...}" label set, enough
+// for test fixtures without depending on the full LogQL parser.
+func parseLabelSet(s string) (labels.Labels, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return labels.EmptyLabels(), fmt.Errorf("invalid label set %q", s)
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return labels.EmptyLabels(), nil
+	}
+
+	builder := labels.NewBuilder(labels.EmptyLabels())
+	for _, pair := range strings.Split(inner, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			return labels.EmptyLabels(), fmt.Errorf("invalid label pair %q in %q", pair, s)
+		}
+		name := strings.TrimSpace(kv[0])
+		value, err := strconv.Unquote(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return labels.EmptyLabels(), fmt.Errorf("invalid label value %q in %q: %w", kv[1], s, err)
+		}
+		builder.Set(name, value)
+	}
+	return builder.Labels(), nil
+}
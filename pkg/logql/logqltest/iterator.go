@@ -0,0 +1,82 @@
+package logqltest
+
+import (
+	"sort"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// entryRow pairs a stream's label string with one of its entries, the unit
+// logqltest's entryIterator advances over.
+type entryRow struct {
+	labels string
+	entry  logproto.Entry
+}
+
+func sortEntryRows(rows []entryRow, dir logproto.Direction) {
+	sort.Slice(rows, func(i, j int) bool {
+		if dir == logproto.BACKWARD {
+			return rows[i].entry.Timestamp.After(rows[j].entry.Timestamp)
+		}
+		return rows[i].entry.Timestamp.Before(rows[j].entry.Timestamp)
+	})
+}
+
+// entryIterator is a minimal, already-sorted iter.EntryIterator over the
+// in-memory rows selected by Querier.SelectLogs.
+type entryIterator struct {
+	rows []entryRow
+	pos  int
+}
+
+func newEntryIterator(rows []entryRow) *entryIterator {
+	return &entryIterator{rows: rows, pos: -1}
+}
+
+func (it *entryIterator) Next() bool {
+	it.pos++
+	return it.pos < len(it.rows)
+}
+
+func (it *entryIterator) Err() error { return nil }
+
+func (it *entryIterator) Labels() string { return it.rows[it.pos].labels }
+
+func (it *entryIterator) StreamHash() uint64 { return 0 }
+
+func (it *entryIterator) At() logproto.Entry { return it.rows[it.pos].entry }
+
+func (it *entryIterator) Close() error { return nil }
+
+// sampleRow pairs a stream's label string with one extracted sample, the
+// unit logqltest's sampleIterator advances over.
+type sampleRow struct {
+	labels string
+	sample logproto.Sample
+}
+
+// sampleIterator is a minimal, already-sorted iter.SampleIterator over the
+// in-memory rows selected by Querier.SelectSamples.
+type sampleIterator struct {
+	rows []sampleRow
+	pos  int
+}
+
+func newSampleIterator(rows []sampleRow) *sampleIterator {
+	return &sampleIterator{rows: rows, pos: -1}
+}
+
+func (it *sampleIterator) Next() bool {
+	it.pos++
+	return it.pos < len(it.rows)
+}
+
+func (it *sampleIterator) Err() error { return nil }
+
+func (it *sampleIterator) Labels() string { return it.rows[it.pos].labels }
+
+func (it *sampleIterator) StreamHash() uint64 { return 0 }
+
+func (it *sampleIterator) At() logproto.Sample { return it.rows[it.pos].sample }
+
+func (it *sampleIterator) Close() error { return nil }
@@ -0,0 +1,32 @@
+package logqltest
+
+import (
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// literalParams is a minimal logql.Params implementation built directly
+// from an already-parsed expression, avoiding a dependency on the request
+// plumbing (SelectLogParams/SelectSampleParams) that real callers use.
+type literalParams struct {
+	queryString string
+	expr        syntax.Expr
+	start, end  time.Time
+	step        time.Duration
+	interval    time.Duration
+	direction   logproto.Direction
+	limit       uint32
+	shards      []string
+}
+
+func (p *literalParams) QueryString() string           { return p.queryString }
+func (p *literalParams) GetExpression() syntax.Expr    { return p.expr }
+func (p *literalParams) Start() time.Time              { return p.start }
+func (p *literalParams) End() time.Time                { return p.end }
+func (p *literalParams) Step() time.Duration           { return p.step }
+func (p *literalParams) Interval() time.Duration       { return p.interval }
+func (p *literalParams) Direction() logproto.Direction { return p.direction }
+func (p *literalParams) Limit() uint32                 { return p.limit }
+func (p *literalParams) Shards() []string              { return p.shards }
@@ -0,0 +1,146 @@
+package logqltest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+)
+
+// stream is a single in-memory log stream loaded via a `load` DSL block.
+type stream struct {
+	labels  labels.Labels
+	entries []logproto.Entry
+}
+
+// Querier is an in-memory logql.Querier backed by streams loaded through
+// the test DSL. It exists so RunTest/RunBuiltinTests can evaluate queries
+// against fixed, deterministic data without a real storage backend.
+type Querier struct {
+	streams []stream
+}
+
+// NewQuerier returns an empty in-memory Querier; use Push to populate it.
+func NewQuerier() *Querier {
+	return &Querier{}
+}
+
+// Push appends a single entry to the stream identified by lbls, creating it
+// on first use, and keeps its entries sorted by timestamp.
+func (q *Querier) Push(lbls labels.Labels, entry logproto.Entry) {
+	key := lbls.String()
+	for i := range q.streams {
+		if q.streams[i].labels.String() == key {
+			q.streams[i].entries = append(q.streams[i].entries, entry)
+			sort.Slice(q.streams[i].entries, func(a, b int) bool {
+				return q.streams[i].entries[a].Timestamp.Before(q.streams[i].entries[b].Timestamp)
+			})
+			return
+		}
+	}
+	q.streams = append(q.streams, stream{labels: lbls, entries: []logproto.Entry{entry}})
+}
+
+// matchingEntries returns, per matching stream, the entries within
+// [start,end) that the given matchers select.
+func (q *Querier) matchingEntries(matchers []*labels.Matcher, start, end int64) []stream {
+	var out []stream
+	for _, s := range q.streams {
+		matched := true
+		for _, m := range matchers {
+			if !m.Matches(s.labels.Get(m.Name)) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		var filtered []logproto.Entry
+		for _, e := range s.entries {
+			ts := e.Timestamp.UnixNano()
+			if ts >= start && ts < end {
+				filtered = append(filtered, e)
+			}
+		}
+		if len(filtered) > 0 {
+			out = append(out, stream{labels: s.labels, entries: filtered})
+		}
+	}
+	return out
+}
+
+// SelectLogs implements logql.Querier.
+func (q *Querier) SelectLogs(_ context.Context, params logql.SelectLogParams) (iter.EntryIterator, error) {
+	expr, err := params.LogSelector()
+	if err != nil {
+		return nil, err
+	}
+	matchers := expr.Matchers()
+	pipeline, err := expr.Pipeline()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := q.matchingEntries(matchers, params.Start.UnixNano(), params.End.UnixNano())
+
+	var rows []entryRow
+	for _, s := range matched {
+		sp, err := pipeline.ForStream(s.labels)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range s.entries {
+			_, _, ok := sp.Process(e.Timestamp.UnixNano(), []byte(e.Line), nil)
+			if ok {
+				rows = append(rows, entryRow{labels: s.labels.String(), entry: e})
+			}
+		}
+	}
+	sortEntryRows(rows, params.Direction)
+
+	return newEntryIterator(rows), nil
+}
+
+// SelectSamples implements logql.Querier.
+func (q *Querier) SelectSamples(_ context.Context, params logql.SelectSampleParams) (iter.SampleIterator, error) {
+	expr, err := params.Expr()
+	if err != nil {
+		return nil, err
+	}
+	selector, err := expr.Selector()
+	if err != nil {
+		return nil, err
+	}
+	extractor, err := expr.Extractor()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := q.matchingEntries(selector.Matchers(), params.Start.UnixNano(), params.End.UnixNano())
+
+	var rows []sampleRow
+	for _, s := range matched {
+		sp, err := extractor.ForStream(s.labels)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range s.entries {
+			value, _, ok := sp.Process(e.Timestamp.UnixNano(), []byte(e.Line), nil)
+			if ok {
+				rows = append(rows, sampleRow{
+					labels: s.labels.String(),
+					sample: logproto.Sample{Timestamp: e.Timestamp.UnixNano(), Value: value},
+				})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].sample.Timestamp < rows[j].sample.Timestamp })
+
+	return newSampleIterator(rows), nil
+}
@@ -0,0 +1,186 @@
+package logqltest
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+//go:embed testdata/*.test
+var testdataFS embed.FS
+
+// epsilon bounds the acceptable floating point error between an expected
+// and an actual sample value, mirroring promqltest's default tolerance.
+const epsilon = 0.0001
+
+// baseTime is the fixed reference point every offset in a test script is
+// relative to, so tests are fully deterministic.
+var baseTime = time.Unix(0, 0).UTC()
+
+// NewEngineFunc builds a logql.Engine backed by the given in-memory
+// Querier. RunTest/RunBuiltinTests call this once per script after loading
+// its data, so callers retain control over EngineOpts/Limits -- e.g. to run
+// the same script through both the current and v2 (EngineOpts.EnableV2Engine)
+// engines.
+type NewEngineFunc func(*Querier) logql.Engine
+
+// RunTest parses input as a test script, loads its streams into a fresh
+// in-memory Querier, and runs its eval commands against newEngine(querier).
+func RunTest(t TestingT, input string, newEngine NewEngineFunc) {
+	t.Helper()
+
+	cmds, err := parseScript(input)
+	if err != nil {
+		t.Errorf("parsing test script: %s", err)
+		t.FailNow()
+		return
+	}
+
+	querier := NewQuerier()
+	for _, c := range cmds {
+		if cmd, ok := c.(loadCmd); ok {
+			for _, p := range cmd.pushes {
+				querier.Push(p.labels, logproto.Entry{
+					Timestamp: baseTime.Add(p.offset),
+					Line:      p.line,
+				})
+			}
+		}
+	}
+
+	engine := newEngine(querier)
+	for _, c := range cmds {
+		if cmd, ok := c.(evalCmd); ok {
+			runEval(t, cmd, engine)
+		}
+	}
+}
+
+func runEval(t TestingT, cmd evalCmd, engine logql.Engine) {
+	t.Helper()
+
+	expr, err := syntax.ParseExpr(cmd.query)
+	if err != nil {
+		t.Errorf("parsing query %q: %s", cmd.query, err)
+		return
+	}
+
+	params := &literalParams{
+		queryString: cmd.query,
+		expr:        expr,
+		direction:   logproto.BACKWARD,
+		limit:       1000,
+	}
+	if cmd.instant {
+		params.start = baseTime.Add(cmd.at)
+		params.end = params.start
+	} else {
+		params.start = baseTime
+		params.end = baseTime.Add(cmd.at)
+		params.step = cmd.step
+		params.interval = cmd.step
+	}
+
+	result, err := engine.Query(params).Exec(context.Background())
+	if err != nil {
+		t.Errorf("evaluating query %q: %s", cmd.query, err)
+		return
+	}
+
+	actual := flattenSamples(result.Data)
+	expected := cmd.expected
+
+	if len(actual) != len(expected) {
+		t.Errorf("query %q: expected %d samples, got %d (%v)", cmd.query, len(expected), len(actual), actual)
+		return
+	}
+
+	sortSamples(expected)
+	sortSamples(actual)
+	for i := range expected {
+		if expected[i].labels.String() != actual[i].labels.String() {
+			t.Errorf("query %q: sample %d: expected labels %s, got %s", cmd.query, i, expected[i].labels, actual[i].labels)
+			continue
+		}
+		if expected[i].at != actual[i].at {
+			t.Errorf("query %q: sample %d (%s): expected time %s, got %s", cmd.query, i, expected[i].labels, expected[i].at, actual[i].at)
+			continue
+		}
+		if math.Abs(expected[i].value-actual[i].value) > epsilon {
+			t.Errorf("query %q: sample %d (%s): expected value %v, got %v", cmd.query, i, expected[i].labels, expected[i].value, actual[i].value)
+		}
+	}
+}
+
+func sortSamples(s []expectedSample) {
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].labels.String() != s[j].labels.String() {
+			return s[i].labels.String() < s[j].labels.String()
+		}
+		return s[i].at < s[j].at
+	})
+}
+
+// flattenSamples converts a query's promql.Vector/Matrix result into the
+// same expectedSample shape used by the DSL, so actual and expected results
+// can be compared uniformly.
+func flattenSamples(data interface{}) []expectedSample {
+	var out []expectedSample
+	switch v := data.(type) {
+	case promql.Vector:
+		for _, s := range v {
+			out = append(out, expectedSample{
+				labels: s.Metric,
+				value:  s.F,
+				at:     time.Duration(s.T) * time.Millisecond,
+			})
+		}
+	case promql.Matrix:
+		for _, series := range v {
+			for _, f := range series.Floats {
+				out = append(out, expectedSample{
+					labels: series.Metric,
+					value:  f.F,
+					at:     time.Duration(f.T) * time.Millisecond,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// RunBuiltinTests runs every test script embedded under testdata/ against
+// newEngine. Downstream projects can wrap TestingT to skip individual cases
+// (by name) that don't apply to an alternate storage backend or engine.
+func RunBuiltinTests(t TestingT, newEngine NewEngineFunc) {
+	t.Helper()
+
+	entries, err := testdataFS.ReadDir("testdata")
+	if err != nil {
+		t.Errorf("reading embedded testdata: %s", err)
+		t.FailNow()
+		return
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		t.Run(name, func(t TestingT) {
+			content, err := testdataFS.ReadFile(fmt.Sprintf("testdata/%s", name))
+			if err != nil {
+				t.Errorf("reading %s: %s", name, err)
+				t.FailNow()
+				return
+			}
+			RunTest(t, string(content), newEngine)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package logqltest provides a deterministic, promqltest-style conformance
+// harness for LogQL queries: a small text DSL describes streams to load and
+// queries to evaluate against them, and RunTest/RunBuiltinTests drive those
+// cases through a real logql.Engine.
+package logqltest
+
+// TestingT holds the *testing.T-like methods that RunTest and
+// RunBuiltinTests require. Its Run takes a func(TestingT) rather than a
+// func(*testing.T), so a *testing.T does not satisfy it directly and is
+// passed in through a small wrapper. It is defined as an interface, rather
+// than depending on *testing.T directly, so that downstream projects can
+// supply their own implementation -- for example one that consults a skip
+// list to exclude cases that don't fit an alternate storage backend or
+// query engine.
+type TestingT interface {
+	Helper()
+	Run(name string, f func(TestingT)) bool
+	Errorf(format string, args ...interface{})
+	FailNow()
+	Skip(args ...interface{})
+}
@@ -0,0 +1,168 @@
+package comparator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FixtureQueryKey canonicalizes the parameters that identify one query
+// comparison, so a response recorded for a query can be looked back up
+// on replay regardless of the order those parameters arrived in.
+type FixtureQueryKey struct {
+	Query     string
+	Start     time.Time
+	End       time.Time
+	Step      time.Duration
+	Direction string
+	Limit     int
+}
+
+// String renders the key deterministically; it doubles as the lookup
+// key for the in-memory replay index.
+func (k FixtureQueryKey) String() string {
+	return fmt.Sprintf("query=%s start=%d end=%d step=%d direction=%s limit=%d",
+		k.Query, k.Start.UnixNano(), k.End.UnixNano(), k.Step.Nanoseconds(), k.Direction, k.Limit)
+}
+
+// fixtureRecord is one line of a fixture file: the query key that
+// produced Response, stored as newline-delimited JSON so fixture files
+// diff well in code review and can be sharded across multiple files.
+type fixtureRecord struct {
+	Key      FixtureQueryKey `json:"key"`
+	Response json.RawMessage `json:"response"`
+}
+
+// FixtureComparator wraps a ResponsesComparator to support record/replay
+// regression testing, inspired by the m3comparator workflow:
+//
+//   - Record(path) appends every "actual" response passed to
+//     CompareWithKey to a newline-delimited JSON fixture file, keyed by
+//     its FixtureQueryKey.
+//   - Replay(path) loads a previously recorded fixture file and serves
+//     its responses as "expected" in place of whatever the caller
+//     passed in.
+//
+// This lets a regression corpus be built once against a trusted version
+// and then replayed in CI against every PR without needing two live
+// backends.
+type FixtureComparator struct {
+	inner ResponsesComparator
+
+	mu         sync.Mutex
+	recordFile *os.File
+	replay     map[string]json.RawMessage
+}
+
+// NewFixtureComparator wraps inner for use with Record and/or Replay.
+func NewFixtureComparator(inner ResponsesComparator) *FixtureComparator {
+	return &FixtureComparator{inner: inner}
+}
+
+// Record opens path for writing, truncating any existing content, and
+// appends every response compared via CompareWithKey to it until
+// StopRecording is called.
+func (f *FixtureComparator) Record(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("open fixture file %q for recording: %w", path, err)
+	}
+
+	f.mu.Lock()
+	f.recordFile = file
+	f.mu.Unlock()
+	return nil
+}
+
+// StopRecording closes the fixture file opened by Record, if any.
+func (f *FixtureComparator) StopRecording() error {
+	f.mu.Lock()
+	file := f.recordFile
+	f.recordFile = nil
+	f.mu.Unlock()
+
+	if file == nil {
+		return nil
+	}
+	return file.Close()
+}
+
+// Replay loads a fixture file written by Record, so subsequent
+// CompareWithKey calls serve its responses as "expected" instead of
+// whatever the caller passes in.
+func (f *FixtureComparator) Replay(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open fixture file %q for replay: %w", path, err)
+	}
+	defer file.Close()
+
+	replay := make(map[string]json.RawMessage)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec fixtureRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("decode fixture record in %q: %w", path, err)
+		}
+		replay[rec.Key.String()] = rec.Response
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read fixture file %q: %w", path, err)
+	}
+
+	f.mu.Lock()
+	f.replay = replay
+	f.mu.Unlock()
+	return nil
+}
+
+// Compare implements ResponsesComparator using the zero FixtureQueryKey.
+// Callers that want recording or replay keyed by query should use
+// CompareWithKey instead.
+func (f *FixtureComparator) Compare(expected, actual []byte, queryEvaluationTime time.Time) (*ComparisonSummary, error) {
+	return f.CompareWithKey(FixtureQueryKey{}, expected, actual, queryEvaluationTime)
+}
+
+// CompareWithKey behaves like Compare, except that a fixture loaded by
+// Replay for key is substituted for expected, and if Record is active
+// actual is appended to the fixture file under key.
+func (f *FixtureComparator) CompareWithKey(key FixtureQueryKey, expected, actual []byte, queryEvaluationTime time.Time) (*ComparisonSummary, error) {
+	f.mu.Lock()
+	if replayed, ok := f.replay[key.String()]; ok {
+		expected = replayed
+	}
+	recordFile := f.recordFile
+	f.mu.Unlock()
+
+	if recordFile != nil {
+		if err := f.appendRecord(recordFile, key, actual); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.inner.Compare(expected, actual, queryEvaluationTime)
+}
+
+func (f *FixtureComparator) appendRecord(file *os.File, key FixtureQueryKey, actual []byte) error {
+	rec := fixtureRecord{Key: key, Response: json.RawMessage(actual)}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode fixture record: %w", err)
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("write fixture record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// histogramSpan is a run of Length consecutive buckets starting Offset
+// buckets after the previous span, mirroring logproto.HistogramSpan and
+// the sparse bucket layout Prometheus native histograms use on the wire.
+type histogramSpan struct {
+	Offset int32  `json:"offset"`
+	Length uint32 `json:"length"`
+}
+
+// sampleHistogram is the native-histogram counterpart of model.SamplePair.
+// It carries the full sparse representation (schema, zero bucket, spans
+// and delta-encoded bucket counts) rather than a resolved bucket list, so
+// the comparator can detect bucket-level mismatches instead of only a
+// summary count/sum.
+type sampleHistogram struct {
+	Schema          int32             `json:"schema"`
+	ZeroThreshold   model.SampleValue `json:"zero_threshold"`
+	ZeroCount       model.SampleValue `json:"zero_count"`
+	Count           model.SampleValue `json:"count"`
+	Sum             model.SampleValue `json:"sum"`
+	PositiveSpans   []histogramSpan   `json:"positive_spans"`
+	PositiveBuckets []float64         `json:"positive_buckets"`
+	NegativeSpans   []histogramSpan   `json:"negative_spans"`
+	NegativeBuckets []float64         `json:"negative_buckets"`
+}
+
+// sampleHistogramPair decodes the same [timestamp, value] tuple shape
+// the JSON API uses for float samples, except the value position holds
+// a histogram object instead of a quoted float.
+type sampleHistogramPair struct {
+	Timestamp model.Time
+	Histogram sampleHistogram
+}
+
+func (p *sampleHistogramPair) UnmarshalJSON(b []byte) error {
+	var tuple [2]json.RawMessage
+	if err := json.Unmarshal(b, &tuple); err != nil {
+		return fmt.Errorf("unmarshal histogram sample pair: %w", err)
+	}
+	if err := json.Unmarshal(tuple[0], &p.Timestamp); err != nil {
+		return fmt.Errorf("unmarshal histogram sample timestamp: %w", err)
+	}
+	if err := json.Unmarshal(tuple[1], &p.Histogram); err != nil {
+		return fmt.Errorf("unmarshal histogram sample value: %w", err)
+	}
+	return nil
+}
+
+// comparableSeries is the matrix-result counterpart of model.SampleStream
+// extended with the Histograms field Prometheus adds once a series
+// carries native-histogram samples, so a range vector mixing float and
+// histogram points decodes in one pass.
+type comparableSeries struct {
+	Metric     model.Metric          `json:"metric"`
+	Values     []model.SamplePair    `json:"values,omitempty"`
+	Histograms []sampleHistogramPair `json:"histograms,omitempty"`
+}
+
+// comparableSample is the vector-result counterpart of comparableSeries:
+// a single point that's either a float sample or a histogram sample.
+type comparableSample struct {
+	Metric    model.Metric
+	Timestamp model.Time
+	Value     model.SampleValue
+	Histogram *sampleHistogram
+}
+
+func (s *comparableSample) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Metric    model.Metric         `json:"metric"`
+		Value     *model.SamplePair    `json:"value"`
+		Histogram *sampleHistogramPair `json:"histogram"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("unmarshal comparable sample: %w", err)
+	}
+
+	s.Metric = v.Metric
+	if v.Value != nil {
+		s.Timestamp = v.Value.Timestamp
+		s.Value = v.Value.Value
+	}
+	if v.Histogram != nil {
+		s.Timestamp = v.Histogram.Timestamp
+		h := v.Histogram.Histogram
+		s.Histogram = &h
+	}
+	return nil
+}
+
+// typedSample returns the comparableSample as a typedSample, the shape
+// compareTypedSample operates on for both matrix and vector results.
+func (s comparableSample) typedSample() typedSample {
+	if s.Histogram != nil {
+		return typedSample{Timestamp: s.Timestamp, IsHistogram: true, Histogram: *s.Histogram}
+	}
+	return typedSample{Timestamp: s.Timestamp, Value: s.Value}
+}
+
+// typedSample is one point on a series after merging its float Values
+// and native-histogram Histograms into a single timestamp-ordered
+// sequence. A series returned by a query over a native-histogram metric
+// can interleave float and histogram samples by timestamp, so the two
+// slices can't just be zipped by index.
+type typedSample struct {
+	Timestamp   model.Time
+	IsHistogram bool
+	Value       model.SampleValue
+	Histogram   sampleHistogram
+}
+
+// mergeSeriesSamples merges s.Values and s.Histograms into a single
+// timestamp-ordered slice of typedSample.
+func mergeSeriesSamples(s comparableSeries) []typedSample {
+	merged := make([]typedSample, 0, len(s.Values)+len(s.Histograms))
+	for _, v := range s.Values {
+		merged = append(merged, typedSample{Timestamp: v.Timestamp, Value: v.Value})
+	}
+	for _, h := range s.Histograms {
+		merged = append(merged, typedSample{Timestamp: h.Timestamp, IsHistogram: true, Histogram: h.Histogram})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Timestamp < merged[j].Timestamp })
+	return merged
+}
+
+func sampleKind(isHistogram bool) string {
+	if isHistogram {
+		return "histogram"
+	}
+	return "float"
+}
+
+// compareTypedSample compares two merged samples that occupy the same
+// position in their respective series.
+func compareTypedSample(expected, actual typedSample, opts SampleComparisonOptions) (cause string, err error) {
+	if expected.Timestamp != actual.Timestamp {
+		return CauseSampleTimestampMismatch, fmt.Errorf("expected timestamp %v but got %v: %w", expected.Timestamp, actual.Timestamp, ErrComparisonMismatch)
+	}
+	if expected.IsHistogram != actual.IsHistogram {
+		return CauseHistogramBucketMismatch, fmt.Errorf("expected %s sample at %v but got %s: %w", sampleKind(expected.IsHistogram), expected.Timestamp, sampleKind(actual.IsHistogram), ErrComparisonMismatch)
+	}
+	if expected.IsHistogram {
+		return compareHistogramPair(expected.Timestamp, expected.Histogram, actual.Histogram, opts)
+	}
+	if !compareSampleValue(expected.Value, actual.Value, opts) {
+		return CauseSampleValueMismatch, fmt.Errorf("expected value %s for timestamp %v but got %s: %w", expected.Value, expected.Timestamp, actual.Value, ErrComparisonMismatch)
+	}
+	return CauseNoMismatch, nil
+}
+
+// compareHistogramPair compares two native-histogram samples at a
+// timestamp the caller has already established matches, checking schema
+// and the zero-bucket threshold exactly (they describe the histogram's
+// bucket layout, not a measured value) and count/sum/bucket contents
+// with the same Tolerance/UseRelativeError semantics compareSampleValue
+// applies to floats.
+func compareHistogramPair(ts model.Time, expected, actual sampleHistogram, opts SampleComparisonOptions) (cause string, err error) {
+	if expected.Schema != actual.Schema {
+		return CauseHistogramSchemaMismatch, fmt.Errorf("expected histogram schema %d at %v but got %d: %w", expected.Schema, ts, actual.Schema, ErrComparisonMismatch)
+	}
+	if expected.ZeroThreshold != actual.ZeroThreshold {
+		return CauseHistogramSchemaMismatch, fmt.Errorf("expected histogram zero threshold %v at %v but got %v: %w", expected.ZeroThreshold, ts, actual.ZeroThreshold, ErrComparisonMismatch)
+	}
+	if !compareSampleValue(expected.ZeroCount, actual.ZeroCount, opts) {
+		return CauseHistogramBucketMismatch, fmt.Errorf("expected histogram zero count %v at %v but got %v: %w", expected.ZeroCount, ts, actual.ZeroCount, ErrComparisonMismatch)
+	}
+	if !compareSampleValue(expected.Count, actual.Count, opts) {
+		return CauseHistogramCountMismatch, fmt.Errorf("expected histogram count %v at %v but got %v: %w", expected.Count, ts, actual.Count, ErrComparisonMismatch)
+	}
+	if !compareSampleValue(expected.Sum, actual.Sum, opts) {
+		return CauseHistogramSumMismatch, fmt.Errorf("expected histogram sum %v at %v but got %v: %w", expected.Sum, ts, actual.Sum, ErrComparisonMismatch)
+	}
+	if cause, err := compareHistogramBuckets("positive", ts, expected.PositiveSpans, expected.PositiveBuckets, actual.PositiveSpans, actual.PositiveBuckets, opts); err != nil {
+		return cause, err
+	}
+	if cause, err := compareHistogramBuckets("negative", ts, expected.NegativeSpans, expected.NegativeBuckets, actual.NegativeSpans, actual.NegativeBuckets, opts); err != nil {
+		return cause, err
+	}
+	return CauseNoMismatch, nil
+}
+
+// compareHistogramBuckets resolves each side's span/delta encoding to
+// absolute per-bucket counts before comparing bucket by bucket, since
+// the same logical bucket layout can be split into spans differently
+// between two otherwise-identical histograms.
+func compareHistogramBuckets(side string, ts model.Time, expectedSpans []histogramSpan, expectedDeltas []float64, actualSpans []histogramSpan, actualDeltas []float64, opts SampleComparisonOptions) (string, error) {
+	expectedBuckets := resolveHistogramBuckets(expectedSpans, expectedDeltas)
+	actualBuckets := resolveHistogramBuckets(actualSpans, actualDeltas)
+
+	if len(expectedBuckets) != len(actualBuckets) {
+		return CauseHistogramBucketMismatch, fmt.Errorf("expected %d %s buckets at %v but got %d: %w", len(expectedBuckets), side, ts, len(actualBuckets), ErrComparisonMismatch)
+	}
+	for bucket, expectedCount := range expectedBuckets {
+		actualCount, ok := actualBuckets[bucket]
+		if !ok {
+			return CauseHistogramBucketMismatch, fmt.Errorf("expected %s bucket %d at %v but it's missing from actual: %w", side, bucket, ts, ErrComparisonMismatch)
+		}
+		if !compareSampleValue(model.SampleValue(expectedCount), model.SampleValue(actualCount), opts) {
+			return CauseHistogramBucketMismatch, fmt.Errorf("expected %s bucket %d count %v at %v but got %v: %w", side, bucket, expectedCount, ts, actualCount, ErrComparisonMismatch)
+		}
+	}
+	return CauseNoMismatch, nil
+}
+
+// resolveHistogramBuckets expands a sparse span/delta encoding into a
+// map from absolute bucket index to bucket count.
+func resolveHistogramBuckets(spans []histogramSpan, deltas []float64) map[int]float64 {
+	buckets := make(map[int]float64, len(deltas))
+	bucketIdx := 0
+	deltaIdx := 0
+	running := 0.0
+	for _, span := range spans {
+		bucketIdx += int(span.Offset)
+		for i := uint32(0); i < span.Length && deltaIdx < len(deltas); i++ {
+			running += deltas[deltaIdx]
+			buckets[bucketIdx] = running
+			deltaIdx++
+			bucketIdx++
+		}
+	}
+	return buckets
+}
+
+// filterVectorOutsideWindow filters out vector samples outside the
+// comparable window, covering both float and histogram samples since
+// comparableSample doesn't distinguish them for this purpose.
+func filterVectorOutsideWindow(samples []comparableSample, skipSample func(time.Time) bool) []comparableSample {
+	filtered := samples[:0]
+	for _, s := range samples {
+		if !skipSample(s.Timestamp.Time()) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
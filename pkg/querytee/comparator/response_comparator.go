@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"strings"
+	"regexp"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -41,6 +41,12 @@ const (
 	CauseStructuredMetadataMismatch      = "structured_metadata_mismatch"
 	CauseParsedLabelsCountMismatch       = "parsed_labels_count_mismatch"
 	CauseParsedLabelsMismatch            = "parsed_labels_mismatch"
+	CauseHistogramBucketMismatch         = "histogram_bucket_mismatch"
+	CauseHistogramSchemaMismatch         = "histogram_schema_mismatch"
+	CauseHistogramCountMismatch          = "histogram_count_mismatch"
+	CauseHistogramSumMismatch            = "histogram_sum_mismatch"
+	CauseWarningsMismatch                = "warnings_mismatch"
+	CauseInfosMismatch                   = "infos_mismatch"
 	CauseUnknown                         = "unknown"
 )
 
@@ -51,12 +57,76 @@ type ResponsesComparator interface {
 type ComparisonSummary struct {
 	Skipped        bool
 	MissingMetrics int
-	// MismatchCause is set when comparison fails with ErrComparisonMismatch.
+	// MismatchCause is the cause of the first recorded mismatch, kept for
+	// callers that only care whether (and roughly why) a comparison
+	// failed. Report carries the full detail.
 	MismatchCause string
+	// Report carries every mismatch found during the comparison, not
+	// just the first, so a correctness audit across many series can
+	// triage all of them in one pass.
+	Report *ComparisonReport
+}
+
+// MismatchRecord describes a single comparison mismatch found while
+// comparing one query's expected and actual responses.
+type MismatchRecord struct {
+	Cause         string
+	Labels        string
+	Timestamp     time.Time
+	ExpectedValue string
+	ActualValue   string
+	Message       string
+}
+
+// ComparisonReport accumulates every mismatch found while comparing one
+// query's expected and actual responses, instead of stopping at the
+// first one. MaxMismatches caps how many records are retained, but
+// CauseCounts is incremented for every mismatch regardless of the cap so
+// Prometheus metrics can be incremented per category rather than only
+// for the first encountered mismatch.
+type ComparisonReport struct {
+	Mismatches  []MismatchRecord
+	CauseCounts map[string]int
+
+	MaxMismatches int
+}
+
+func newComparisonReport(maxMismatches int) *ComparisonReport {
+	return &ComparisonReport{CauseCounts: make(map[string]int), MaxMismatches: maxMismatches}
+}
+
+// add records a mismatch, subject to MaxMismatches.
+func (r *ComparisonReport) add(rec MismatchRecord) {
+	r.CauseCounts[rec.Cause]++
+	if r.MaxMismatches > 0 && len(r.Mismatches) >= r.MaxMismatches {
+		return
+	}
+	r.Mismatches = append(r.Mismatches, rec)
+}
+
+// firstCause returns the cause of the first recorded mismatch, or
+// CauseNoMismatch if the report is empty.
+func (r *ComparisonReport) firstCause() string {
+	if len(r.Mismatches) == 0 {
+		return CauseNoMismatch
+	}
+	return r.Mismatches[0].Cause
+}
+
+// err returns ErrComparisonMismatch describing the first recorded
+// mismatch if the report is non-empty, and nil otherwise.
+func (r *ComparisonReport) err() error {
+	if len(r.Mismatches) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", r.Mismatches[0].Message, ErrComparisonMismatch)
 }
 
 // SamplesComparatorFunc helps with comparing different types of samples coming from /api/v1/query and /api/v1/query_range routes.
-type SamplesComparatorFunc func(expected, actual json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error)
+// Mismatches are appended to report rather than returned directly; the
+// returned error is reserved for operational failures such as an
+// unmarshalling error.
+type SamplesComparatorFunc func(expected, actual json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions, report *ComparisonReport) (skipped bool, missingMetrics int, err error)
 
 type SamplesResponse struct {
 	Status string
@@ -64,6 +134,12 @@ type SamplesResponse struct {
 		ResultType string
 		Result     json.RawMessage
 	}
+	// Warnings and Infos carry PromQL annotations (e.g. "PromQLWarning:
+	// counter was reset" or "PromQLInfo: ...") returned alongside a
+	// query result, newer additions to the API response shape that sit
+	// next to status/data rather than inside it.
+	Warnings []string
+	Infos    []string
 }
 
 type SampleComparisonOptions struct {
@@ -71,6 +147,52 @@ type SampleComparisonOptions struct {
 	UseRelativeError  bool
 	SkipRecentSamples time.Duration
 	SkipSamplesBefore time.Time
+
+	// MaxMismatchesPerQuery caps how many MismatchRecords are retained
+	// per query in ComparisonReport.Mismatches, so comparing a query
+	// with thousands of diverging series doesn't build an unbounded
+	// report. 0 means unlimited. CauseCounts is unaffected by the cap.
+	MaxMismatchesPerQuery int
+
+	// IgnoreAnnotations disables warnings/infos comparison entirely.
+	IgnoreAnnotations bool
+	// IgnoreAnnotationPatterns suppresses individual warnings/infos that
+	// match any of the given patterns, so an operator can silence known
+	// noisy annotations during a rollout while still catching new ones.
+	IgnoreAnnotationPatterns []*regexp.Regexp
+
+	// IgnoreStructuredMetadataKeys exempts the named structured-metadata
+	// labels from stream comparison, e.g. __stream_shard__ or trace IDs
+	// that legitimately vary between the two backends being compared.
+	IgnoreStructuredMetadataKeys []string
+	// IgnoreParsedLabelKeys exempts the named parsed labels from stream
+	// comparison, for the same reason as IgnoreStructuredMetadataKeys.
+	IgnoreParsedLabelKeys []string
+}
+
+// filterAnnotations drops entries matched by opts.IgnoreAnnotationPatterns.
+func (opts *SampleComparisonOptions) filterAnnotations(annotations []string) []string {
+	if len(opts.IgnoreAnnotationPatterns) == 0 {
+		return annotations
+	}
+
+	filtered := make([]string, 0, len(annotations))
+	for _, a := range annotations {
+		if opts.ignoreAnnotation(a) {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	return filtered
+}
+
+func (opts *SampleComparisonOptions) ignoreAnnotation(annotation string) bool {
+	for _, pattern := range opts.IgnoreAnnotationPatterns {
+		if pattern.MatchString(annotation) {
+			return true
+		}
+	}
+	return false
 }
 
 func (opts *SampleComparisonOptions) SkipSample(sampleTime, evaluationTime time.Time) bool {
@@ -121,12 +243,21 @@ func (s *SamplesComparator) Compare(expectedResponse, actualResponse []byte, eva
 		return nil, fmt.Errorf("unable to unmarshal actual response: %w", err)
 	}
 
+	report := newComparisonReport(s.opts.MaxMismatchesPerQuery)
+
 	if expected.Status != actual.Status {
-		return &ComparisonSummary{MismatchCause: CauseStatusMismatch}, fmt.Errorf("expected status %s but got %s: %w", expected.Status, actual.Status, ErrComparisonMismatch)
+		report.add(MismatchRecord{Cause: CauseStatusMismatch, Message: fmt.Sprintf("expected status %s but got %s", expected.Status, actual.Status)})
+		return s.summary(report), report.err()
 	}
 
 	if expected.Data.ResultType != actual.Data.ResultType {
-		return &ComparisonSummary{MismatchCause: CauseResultTypeMismatch}, fmt.Errorf("expected resultType %s but got %s: %w", expected.Data.ResultType, actual.Data.ResultType, ErrComparisonMismatch)
+		report.add(MismatchRecord{Cause: CauseResultTypeMismatch, Message: fmt.Sprintf("expected resultType %s but got %s", expected.Data.ResultType, actual.Data.ResultType)})
+		return s.summary(report), report.err()
+	}
+
+	if !s.opts.IgnoreAnnotations {
+		compareAnnotations(report, CauseWarningsMismatch, "warning", s.opts.filterAnnotations(expected.Warnings), s.opts.filterAnnotations(actual.Warnings))
+		compareAnnotations(report, CauseInfosMismatch, "info", s.opts.filterAnnotations(expected.Infos), s.opts.filterAnnotations(actual.Infos))
 	}
 
 	comparator, ok := s.sampleTypesComparator[expected.Data.ResultType]
@@ -134,19 +265,61 @@ func (s *SamplesComparator) Compare(expectedResponse, actualResponse []byte, eva
 		return nil, fmt.Errorf("resultType %s not registered for comparison", expected.Data.ResultType)
 	}
 
-	return comparator(expected.Data.Result, actual.Data.Result, evaluationTime, s.opts)
+	skipped, missingMetrics, err := comparator(expected.Data.Result, actual.Data.Result, evaluationTime, s.opts, report)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := s.summary(report)
+	summary.Skipped = skipped
+	summary.MissingMetrics = missingMetrics
+	return summary, report.err()
 }
 
-func compareMatrix(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error) {
-	var expected, actual model.Matrix
+func (s *SamplesComparator) summary(report *ComparisonReport) *ComparisonSummary {
+	return &ComparisonSummary{MismatchCause: report.firstCause(), Report: report}
+}
 
-	err := json.Unmarshal(expectedRaw, &expected)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal expected matrix: %w", err)
+// compareAnnotations diffs expected and actual as multisets, since
+// PromQL annotations aren't guaranteed to be emitted in any particular
+// order and the same annotation can legitimately appear once per
+// contributing series.
+func compareAnnotations(report *ComparisonReport, cause, kind string, expected, actual []string) {
+	expectedCounts := countAnnotations(expected)
+	actualCounts := countAnnotations(actual)
+
+	if len(expectedCounts) != len(actualCounts) {
+		report.add(MismatchRecord{Cause: cause, Message: fmt.Sprintf("expected %d distinct %s(s) but got %d", len(expectedCounts), kind, len(actualCounts))})
 	}
-	err = json.Unmarshal(actualRaw, &actual)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal actual matrix: %w", err)
+
+	for annotation, expectedCount := range expectedCounts {
+		actualCount, ok := actualCounts[annotation]
+		if !ok {
+			report.add(MismatchRecord{Cause: cause, Message: fmt.Sprintf("expected %s %q missing from actual response", kind, annotation)})
+			continue
+		}
+		if actualCount != expectedCount {
+			report.add(MismatchRecord{Cause: cause, Message: fmt.Sprintf("expected %s %q to appear %d time(s) but got %d", kind, annotation, expectedCount, actualCount)})
+		}
+	}
+}
+
+func countAnnotations(annotations []string) map[string]int {
+	counts := make(map[string]int, len(annotations))
+	for _, a := range annotations {
+		counts[a]++
+	}
+	return counts
+}
+
+func compareMatrix(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions, report *ComparisonReport) (skipped bool, missingMetrics int, err error) {
+	var expected, actual []comparableSeries
+
+	if err := json.Unmarshal(expectedRaw, &expected); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal expected matrix: %w", err)
+	}
+	if err := json.Unmarshal(actualRaw, &actual); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal actual matrix: %w", err)
 	}
 
 	// Filter out samples outside the comparable window
@@ -161,68 +334,91 @@ func compareMatrix(expectedRaw, actualRaw json.RawMessage, evaluationTime time.T
 
 	// If both matrices are empty after filtering, we can skip comparison
 	if len(expected) == 0 && len(actual) == 0 {
-		return &ComparisonSummary{Skipped: true}, nil
+		return true, 0, nil
 	}
 
 	if len(expected) != len(actual) {
-		// TODO: log the missing metrics
-		return &ComparisonSummary{MismatchCause: CauseMetricCountMismatch}, fmt.Errorf("expected %d metrics but got %d: %w", len(expected),
-			len(actual), ErrComparisonMismatch)
+		report.add(MismatchRecord{Cause: CauseMetricCountMismatch, Message: fmt.Sprintf("expected %d metrics but got %d", len(expected), len(actual))})
 	}
 
-	metricFingerprintToIndexMap := make(map[model.Fingerprint]int, len(expected))
+	metricFingerprintToIndexMap := make(map[model.Fingerprint]int, len(actual))
 	for i, actualMetric := range actual {
 		metricFingerprintToIndexMap[actualMetric.Metric.Fingerprint()] = i
 	}
 
+	missing := 0
 	for _, expectedMetric := range expected {
 		actualMetricIndex, ok := metricFingerprintToIndexMap[expectedMetric.Metric.Fingerprint()]
 		if !ok {
-			return &ComparisonSummary{MismatchCause: CauseMetricMissing}, fmt.Errorf("expected metric %s missing from actual response: %w", expectedMetric.Metric, ErrComparisonMismatch)
+			missing++
+			report.add(MismatchRecord{Cause: CauseMetricMissing, Labels: expectedMetric.Metric.String(), Message: fmt.Sprintf("expected metric %s missing from actual response", expectedMetric.Metric)})
+			continue
 		}
 
-		actualMetric := actual[actualMetricIndex]
-
-		cause, err := compareMatrixSamples(expectedMetric, actualMetric, opts)
-		if err != nil {
-			return &ComparisonSummary{MismatchCause: cause}, fmt.Errorf("%w\nExpected result for series:\n%v\n\nActual result for series:\n%v", err, expectedMetric, actualMetric)
-		}
+		compareMatrixSamples(expectedMetric, actual[actualMetricIndex], opts, report)
 	}
 
-	return nil, nil
+	return false, missing, nil
 }
 
-func compareMatrixSamples(expected, actual *model.SampleStream, opts SampleComparisonOptions) (cause string, err error) {
-	expectedEntriesCount := len(expected.Values)
-	actualEntriesCount := len(actual.Values)
+func compareMatrixSamples(expected, actual comparableSeries, opts SampleComparisonOptions, report *ComparisonReport) {
+	// Values and Histograms can each hold part of a series' points, and a
+	// series over a native-histogram metric can interleave the two by
+	// timestamp, so merge both into one ordered sequence before comparing.
+	expectedSamples := mergeSeriesSamples(expected)
+	actualSamples := mergeSeriesSamples(actual)
+
+	expectedEntriesCount := len(expectedSamples)
+	actualEntriesCount := len(actualSamples)
 
 	if expectedEntriesCount != actualEntriesCount {
-		err := fmt.Errorf("expected %d samples for metric %s but got %d: %w", expectedEntriesCount, expected.Metric, actualEntriesCount, ErrComparisonMismatch)
+		msg := fmt.Sprintf("expected %d samples for metric %s but got %d", expectedEntriesCount, expected.Metric, actualEntriesCount)
 		if actualEntriesCount > 0 && expectedEntriesCount > 0 {
-			level.Error(util_log.Logger).Log("msg", err.Error(),
-				"oldest-expected-ts", expected.Values[0].Timestamp,
-				"newest-expected-ts", expected.Values[expectedEntriesCount-1].Timestamp,
-				"oldest-actual-ts", actual.Values[0].Timestamp,
-				"newest-actual-ts", actual.Values[actualEntriesCount-1].Timestamp)
+			level.Error(util_log.Logger).Log("msg", msg,
+				"oldest-expected-ts", expectedSamples[0].Timestamp,
+				"newest-expected-ts", expectedSamples[expectedEntriesCount-1].Timestamp,
+				"oldest-actual-ts", actualSamples[0].Timestamp,
+				"newest-actual-ts", actualSamples[actualEntriesCount-1].Timestamp)
 		}
-		return CauseSampleCountMismatch, err
+		report.add(MismatchRecord{Cause: CauseSampleCountMismatch, Labels: expected.Metric.String(), Message: msg})
+		return
 	}
 
-	for i := range expected.Values {
-		cause, err := compareSamplePair(expected.Values[i], actual.Values[i], opts)
-		if err != nil {
-			return cause, fmt.Errorf("float sample pair does not match for metric %s: %w", expected.Metric, err)
-		}
+	for i := range expectedSamples {
+		compareTypedSampleInto(expected.Metric, expectedSamples[i], actualSamples[i], opts, report)
 	}
+}
 
-	return CauseNoMismatch, nil
+// compareTypedSampleInto compares two typed samples belonging to metric
+// and, on mismatch, appends a MismatchRecord to report rather than
+// returning, so the caller can keep comparing the rest of the series.
+func compareTypedSampleInto(metric model.Metric, expected, actual typedSample, opts SampleComparisonOptions, report *ComparisonReport) {
+	cause, err := compareTypedSample(expected, actual, opts)
+	if err == nil {
+		return
+	}
+	report.add(MismatchRecord{
+		Cause:         cause,
+		Labels:        metric.String(),
+		Timestamp:     expected.Timestamp.Time(),
+		ExpectedValue: typedSampleValueString(expected),
+		ActualValue:   typedSampleValueString(actual),
+		Message:       fmt.Sprintf("sample pair does not match for metric %s: %s", metric, err),
+	})
+}
+
+func typedSampleValueString(s typedSample) string {
+	if s.IsHistogram {
+		return fmt.Sprintf("%+v", s.Histogram)
+	}
+	return s.Value.String()
 }
 
-func filterSamplesOutsideWindow(matrix model.Matrix, skipSample func(time.Time) bool) model.Matrix {
+func filterSamplesOutsideWindow(matrix []comparableSeries, skipSample func(time.Time) bool) []comparableSeries {
 	result := matrix[:0] // Reuse the original slice capacity while starting with length 0
 
 	for _, series := range matrix {
-		// Reuse the original Values slice
+		// Reuse the original Values/Histograms slices
 		filteredValues := series.Values[:0]
 		for _, sample := range series.Values {
 			if !skipSample(sample.Timestamp.Time()) {
@@ -230,8 +426,16 @@ func filterSamplesOutsideWindow(matrix model.Matrix, skipSample func(time.Time)
 			}
 		}
 
-		if len(filteredValues) > 0 {
+		filteredHistograms := series.Histograms[:0]
+		for _, h := range series.Histograms {
+			if !skipSample(h.Timestamp.Time()) {
+				filteredHistograms = append(filteredHistograms, h)
+			}
+		}
+
+		if len(filteredValues) > 0 || len(filteredHistograms) > 0 {
 			series.Values = filteredValues
+			series.Histograms = filteredHistograms
 			result = append(result, series)
 		}
 	}
@@ -239,115 +443,84 @@ func filterSamplesOutsideWindow(matrix model.Matrix, skipSample func(time.Time)
 	return result
 }
 
-func compareVector(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error) {
-	var expected, actual model.Vector
+func compareVector(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions, report *ComparisonReport) (skipped bool, missingMetrics int, err error) {
+	var expected, actual []comparableSample
 
-	err := json.Unmarshal(expectedRaw, &expected)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal expected vector: %w", err)
+	if err := json.Unmarshal(expectedRaw, &expected); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal expected vector: %w", err)
 	}
-
-	err = json.Unmarshal(actualRaw, &actual)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal actual vector: %w", err)
+	if err := json.Unmarshal(actualRaw, &actual); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal actual vector: %w", err)
 	}
 
 	// Filter out samples outside the comparable windows
 	if !opts.SkipSamplesBefore.IsZero() || opts.SkipRecentSamples > 0 {
-		filtered := expected[:0]
-		for i := range expected {
-			if !opts.SkipSample(expected[i].Timestamp.Time(), evaluationTime) {
-				filtered = append(filtered, expected[i])
-			}
-		}
-		expected = filtered
-
-		filtered = actual[:0]
-		for i := range actual {
-			if !opts.SkipSample(actual[i].Timestamp.Time(), evaluationTime) {
-				filtered = append(filtered, actual[i])
-			}
-		}
-		actual = filtered
+		expected = filterVectorOutsideWindow(expected, func(t time.Time) bool {
+			return opts.SkipSample(t, evaluationTime)
+		})
+		actual = filterVectorOutsideWindow(actual, func(t time.Time) bool {
+			return opts.SkipSample(t, evaluationTime)
+		})
 	}
 
 	if len(expected) == 0 && len(actual) == 0 {
-		return &ComparisonSummary{Skipped: true}, nil
+		return true, 0, nil
 	}
 
 	if len(expected) != len(actual) {
-		return &ComparisonSummary{MismatchCause: CauseMetricCountMismatch}, fmt.Errorf("expected %d metrics but got %d: %w", len(expected),
-			len(actual), ErrComparisonMismatch)
+		report.add(MismatchRecord{Cause: CauseMetricCountMismatch, Message: fmt.Sprintf("expected %d metrics but got %d", len(expected), len(actual))})
 	}
 
-	metricFingerprintToIndexMap := make(map[model.Fingerprint]int, len(expected))
+	metricFingerprintToIndexMap := make(map[model.Fingerprint]int, len(actual))
 	for i, actualMetric := range actual {
 		metricFingerprintToIndexMap[actualMetric.Metric.Fingerprint()] = i
 	}
 
-	missingMetrics := make([]model.Metric, 0)
+	missing := 0
 	for _, expectedMetric := range expected {
 		actualMetricIndex, ok := metricFingerprintToIndexMap[expectedMetric.Metric.Fingerprint()]
 		if !ok {
-			missingMetrics = append(missingMetrics, expectedMetric.Metric)
+			missing++
+			report.add(MismatchRecord{Cause: CauseMetricMissing, Labels: expectedMetric.Metric.String(), Message: fmt.Sprintf("expected metric %s missing from actual response", expectedMetric.Metric)})
 			continue
 		}
 
-		// TODO: collect errors instead of returning.
-		actualMetric := actual[actualMetricIndex]
-		cause, err := compareSamplePair(model.SamplePair{
-			Timestamp: expectedMetric.Timestamp,
-			Value:     expectedMetric.Value,
-		}, model.SamplePair{
-			Timestamp: actualMetric.Timestamp,
-			Value:     actualMetric.Value,
-		}, opts)
-		if err != nil {
-			return &ComparisonSummary{MismatchCause: cause}, fmt.Errorf("sample pair not matching for metric %s: %w", expectedMetric.Metric, err)
-		}
-	}
-
-	if len(missingMetrics) > 0 {
-		var b strings.Builder
-		for i, m := range missingMetrics {
-			if i > 0 {
-				b.WriteString(", ")
-			}
-			b.WriteString(m.String())
-		}
-		return &ComparisonSummary{MissingMetrics: len(missingMetrics), MismatchCause: CauseMetricMissing}, fmt.Errorf("expected metric(s) [%s] missing from actual response: %w", b.String(), ErrComparisonMismatch)
+		compareTypedSampleInto(expectedMetric.Metric, expectedMetric.typedSample(), actual[actualMetricIndex].typedSample(), opts, report)
 	}
 
-	return nil, nil
+	return false, missing, nil
 }
 
-func compareScalar(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error) {
+func compareScalar(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions, report *ComparisonReport) (skipped bool, missingMetrics int, err error) {
 	var expected, actual model.Scalar
-	err := json.Unmarshal(expectedRaw, &expected)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal expected scalar: %w", err)
+	if err := json.Unmarshal(expectedRaw, &expected); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal expected scalar: %w", err)
 	}
-
-	err = json.Unmarshal(actualRaw, &actual)
-	if err != nil {
-		return nil, fmt.Errorf("unable to actual expected scalar: %w", err)
+	if err := json.Unmarshal(actualRaw, &actual); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal actual scalar: %w", err)
 	}
 
 	if opts.SkipSample(expected.Timestamp.Time(), evaluationTime) && opts.SkipSample(actual.Timestamp.Time(), evaluationTime) {
-		return &ComparisonSummary{Skipped: true}, nil
+		return true, 0, nil
 	}
 
-	cause, err := compareSamplePair(model.SamplePair{
+	cause, cmpErr := compareSamplePair(model.SamplePair{
 		Timestamp: expected.Timestamp,
 		Value:     expected.Value,
 	}, model.SamplePair{
 		Timestamp: actual.Timestamp,
 		Value:     actual.Value,
 	}, opts)
-	if err != nil {
-		return &ComparisonSummary{MismatchCause: cause}, err
+	if cmpErr != nil {
+		report.add(MismatchRecord{
+			Cause:         cause,
+			Timestamp:     expected.Timestamp.Time(),
+			ExpectedValue: expected.Value.String(),
+			ActualValue:   actual.Value.String(),
+			Message:       cmpErr.Error(),
+		})
 	}
-	return nil, nil
+	return false, 0, nil
 }
 
 func compareSamplePair(expected, actual model.SamplePair, opts SampleComparisonOptions) (cause string, err error) {
@@ -378,16 +551,14 @@ func compareSampleValue(first, second model.SampleValue, opts SampleComparisonOp
 	return math.Abs(f-s) <= opts.Tolerance
 }
 
-func compareStreams(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error) {
+func compareStreams(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions, report *ComparisonReport) (skipped bool, missingMetrics int, err error) {
 	var expected, actual loghttp.Streams
 
-	err := jsoniter.Unmarshal(expectedRaw, &expected)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal expected streams: %w", err)
+	if err := jsoniter.Unmarshal(expectedRaw, &expected); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal expected streams: %w", err)
 	}
-	err = jsoniter.Unmarshal(actualRaw, &actual)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal actual streams: %w", err)
+	if err := jsoniter.Unmarshal(actualRaw, &actual); err != nil {
+		return false, 0, fmt.Errorf("unable to unmarshal actual streams: %w", err)
 	}
 
 	// Filter out entries outside the comparable window
@@ -402,71 +573,115 @@ func compareStreams(expectedRaw, actualRaw json.RawMessage, evaluationTime time.
 
 	// If both streams are empty after filtering, we can skip comparison
 	if len(expected) == 0 && len(actual) == 0 {
-		return &ComparisonSummary{Skipped: true}, nil
+		return true, 0, nil
 	}
 
 	if len(expected) != len(actual) {
-		// TODO: log the missing stream
-		return &ComparisonSummary{MismatchCause: CauseStreamCountMismatch}, fmt.Errorf("expected %d streams but got %d: %w", len(expected), len(actual), ErrComparisonMismatch)
+		report.add(MismatchRecord{Cause: CauseStreamCountMismatch, Message: fmt.Sprintf("expected %d streams but got %d", len(expected), len(actual))})
 	}
 
-	streamLabelsToIndexMap := make(map[string]int, len(expected))
+	streamLabelsToIndexMap := make(map[string]int, len(actual))
 	for i, actualStream := range actual {
 		streamLabelsToIndexMap[actualStream.Labels.String()] = i
 	}
 
+	missing := 0
 	for _, expectedStream := range expected {
 		actualStreamIndex, ok := streamLabelsToIndexMap[expectedStream.Labels.String()]
 		if !ok {
-			return &ComparisonSummary{MismatchCause: CauseStreamMissing}, fmt.Errorf("expected stream %s missing from actual response: %w", expectedStream.Labels, ErrComparisonMismatch)
+			missing++
+			report.add(MismatchRecord{Cause: CauseStreamMissing, Labels: expectedStream.Labels.String(), Message: fmt.Sprintf("expected stream %s missing from actual response", expectedStream.Labels)})
+			continue
 		}
 
-		actualStream := actual[actualStreamIndex]
-		expectedValuesLen := len(expectedStream.Entries)
-		actualValuesLen := len(actualStream.Entries)
-
-		if expectedValuesLen != actualValuesLen {
-			err := fmt.Errorf("expected %d values for stream %s but got %d: %w", expectedValuesLen,
-				expectedStream.Labels, actualValuesLen, ErrComparisonMismatch)
-			if expectedValuesLen > 0 && actualValuesLen > 0 {
-				// assuming BACKWARD search since that is the default ordering
-				level.Error(util_log.Logger).Log("msg", err.Error(), "newest-expected-ts", expectedStream.Entries[0].Timestamp.UnixNano(),
-					"oldest-expected-ts", expectedStream.Entries[expectedValuesLen-1].Timestamp.UnixNano(),
-					"newest-actual-ts", actualStream.Entries[0].Timestamp.UnixNano(), "oldest-actual-ts", actualStream.Entries[actualValuesLen-1].Timestamp.UnixNano())
-			}
-			return &ComparisonSummary{MismatchCause: CauseStreamEntryCountMismatch}, err
+		compareStreamEntries(expectedStream, actual[actualStreamIndex], opts, report)
+	}
+
+	return false, missing, nil
+}
+
+// labelsToMap converts lbls to a map[string]string, dropping any label
+// whose name appears in ignoreKeys, so callers can diff label sets
+// without relying on labels.Labels' ordering, which Loki does not
+// guarantee across ingesters/queriers.
+func labelsToMap(lbls labels.Labels, ignoreKeys []string) map[string]string {
+	ignore := make(map[string]struct{}, len(ignoreKeys))
+	for _, k := range ignoreKeys {
+		ignore[k] = struct{}{}
+	}
+	m := make(map[string]string, len(lbls))
+	for _, l := range lbls {
+		if _, ok := ignore[l.Name]; ok {
+			continue
 		}
+		m[l.Name] = l.Value
+	}
+	return m
+}
 
-		for i, expectedSamplePair := range expectedStream.Entries {
-			actualSamplePair := actualStream.Entries[i]
-			if !expectedSamplePair.Timestamp.Equal(actualSamplePair.Timestamp) {
-				return &ComparisonSummary{MismatchCause: CauseStreamTimestampMismatch}, fmt.Errorf("expected timestamp %v but got %v for stream %s: %w", expectedSamplePair.Timestamp.UnixNano(),
-					actualSamplePair.Timestamp.UnixNano(), expectedStream.Labels, ErrComparisonMismatch)
-			}
-			if expectedSamplePair.Line != actualSamplePair.Line {
-				return &ComparisonSummary{MismatchCause: CauseStreamLineMismatch}, fmt.Errorf("expected line %s for timestamp %v but got %s for stream %s: %w", expectedSamplePair.Line,
-					expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.Line, expectedStream.Labels, ErrComparisonMismatch)
-			}
-			if expectedSamplePair.StructuredMetadata.Len() != actualSamplePair.StructuredMetadata.Len() {
-				return &ComparisonSummary{MismatchCause: CauseStructuredMetadataCountMismatch}, fmt.Errorf("expected %d metadata pairs for timestamp %v but got %d pairs for stream %s: %w", expectedSamplePair.StructuredMetadata.Len(),
-					expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.StructuredMetadata.Len(), expectedStream.Labels, ErrComparisonMismatch)
-			}
-			if !labels.Equal(expectedSamplePair.StructuredMetadata, actualSamplePair.StructuredMetadata) {
-				return &ComparisonSummary{MismatchCause: CauseStructuredMetadataMismatch}, fmt.Errorf("expected metadata %v for timestamp %v but got %v for stream %s: %w", expectedSamplePair.StructuredMetadata.String(),
-					expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.StructuredMetadata.String(), expectedStream.Labels, ErrComparisonMismatch)
-			}
-			if expectedSamplePair.Parsed.Len() != actualSamplePair.Parsed.Len() {
-				return &ComparisonSummary{MismatchCause: CauseParsedLabelsCountMismatch}, fmt.Errorf("expected %d parsed label pairs for timestamp %v but got %d pairs for stream %s: %w", expectedSamplePair.Parsed.Len(),
-					expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.Parsed.Len(), expectedStream.Labels, ErrComparisonMismatch)
-			}
-			if !labels.Equal(expectedSamplePair.StructuredMetadata, actualSamplePair.StructuredMetadata) {
-				return &ComparisonSummary{MismatchCause: CauseParsedLabelsMismatch}, fmt.Errorf("expected parsed labels %v for timestamp %v but got %v for stream %s: %w", expectedSamplePair.Parsed.String(),
-					expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.Parsed.String(), expectedStream.Labels, ErrComparisonMismatch)
-			}
+func mapsEqual(expected, actual map[string]string) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for k, v := range expected {
+		if av, ok := actual[k]; !ok || av != v {
+			return false
+		}
+	}
+	return true
+}
+
+// compareStreamEntries compares the entries of two streams the caller
+// has already matched by label set, appending every mismatch found to
+// report instead of stopping at the first one.
+func compareStreamEntries(expectedStream, actualStream loghttp.Stream, opts SampleComparisonOptions, report *ComparisonReport) {
+	expectedValuesLen := len(expectedStream.Entries)
+	actualValuesLen := len(actualStream.Entries)
+
+	if expectedValuesLen != actualValuesLen {
+		msg := fmt.Sprintf("expected %d values for stream %s but got %d", expectedValuesLen, expectedStream.Labels, actualValuesLen)
+		if expectedValuesLen > 0 && actualValuesLen > 0 {
+			// assuming BACKWARD search since that is the default ordering
+			level.Error(util_log.Logger).Log("msg", msg, "newest-expected-ts", expectedStream.Entries[0].Timestamp.UnixNano(),
+				"oldest-expected-ts", expectedStream.Entries[expectedValuesLen-1].Timestamp.UnixNano(),
+				"newest-actual-ts", actualStream.Entries[0].Timestamp.UnixNano(), "oldest-actual-ts", actualStream.Entries[actualValuesLen-1].Timestamp.UnixNano())
 		}
+		report.add(MismatchRecord{Cause: CauseStreamEntryCountMismatch, Labels: expectedStream.Labels.String(), Message: msg})
+		return
 	}
 
-	return nil, nil
+	for i, expectedSamplePair := range expectedStream.Entries {
+		actualSamplePair := actualStream.Entries[i]
+		if !expectedSamplePair.Timestamp.Equal(actualSamplePair.Timestamp) {
+			report.add(MismatchRecord{Cause: CauseStreamTimestampMismatch, Labels: expectedStream.Labels.String(), Timestamp: expectedSamplePair.Timestamp,
+				Message: fmt.Sprintf("expected timestamp %v but got %v for stream %s", expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.Timestamp.UnixNano(), expectedStream.Labels)})
+			continue
+		}
+		if expectedSamplePair.Line != actualSamplePair.Line {
+			report.add(MismatchRecord{Cause: CauseStreamLineMismatch, Labels: expectedStream.Labels.String(), Timestamp: expectedSamplePair.Timestamp,
+				ExpectedValue: expectedSamplePair.Line, ActualValue: actualSamplePair.Line,
+				Message: fmt.Sprintf("expected line %s for timestamp %v but got %s for stream %s", expectedSamplePair.Line, expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.Line, expectedStream.Labels)})
+		}
+		expectedMetadata := labelsToMap(expectedSamplePair.StructuredMetadata, opts.IgnoreStructuredMetadataKeys)
+		actualMetadata := labelsToMap(actualSamplePair.StructuredMetadata, opts.IgnoreStructuredMetadataKeys)
+		if len(expectedMetadata) != len(actualMetadata) {
+			report.add(MismatchRecord{Cause: CauseStructuredMetadataCountMismatch, Labels: expectedStream.Labels.String(), Timestamp: expectedSamplePair.Timestamp,
+				Message: fmt.Sprintf("expected %d metadata pairs for timestamp %v but got %d pairs for stream %s", len(expectedMetadata), expectedSamplePair.Timestamp.UnixNano(), len(actualMetadata), expectedStream.Labels)})
+		} else if !mapsEqual(expectedMetadata, actualMetadata) {
+			report.add(MismatchRecord{Cause: CauseStructuredMetadataMismatch, Labels: expectedStream.Labels.String(), Timestamp: expectedSamplePair.Timestamp,
+				Message: fmt.Sprintf("expected metadata %v for timestamp %v but got %v for stream %s", expectedSamplePair.StructuredMetadata.String(), expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.StructuredMetadata.String(), expectedStream.Labels)})
+		}
+
+		expectedParsed := labelsToMap(expectedSamplePair.Parsed, opts.IgnoreParsedLabelKeys)
+		actualParsed := labelsToMap(actualSamplePair.Parsed, opts.IgnoreParsedLabelKeys)
+		if len(expectedParsed) != len(actualParsed) {
+			report.add(MismatchRecord{Cause: CauseParsedLabelsCountMismatch, Labels: expectedStream.Labels.String(), Timestamp: expectedSamplePair.Timestamp,
+				Message: fmt.Sprintf("expected %d parsed label pairs for timestamp %v but got %d pairs for stream %s", len(expectedParsed), expectedSamplePair.Timestamp.UnixNano(), len(actualParsed), expectedStream.Labels)})
+		} else if !mapsEqual(expectedParsed, actualParsed) {
+			report.add(MismatchRecord{Cause: CauseParsedLabelsMismatch, Labels: expectedStream.Labels.String(), Timestamp: expectedSamplePair.Timestamp,
+				Message: fmt.Sprintf("expected parsed labels %v for timestamp %v but got %v for stream %s", expectedSamplePair.Parsed.String(), expectedSamplePair.Timestamp.UnixNano(), actualSamplePair.Parsed.String(), expectedStream.Labels)})
+		}
+	}
 }
 
 // filterStreamsOutsideWindow filters out entries that are outside the comparable window
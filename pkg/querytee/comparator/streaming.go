@@ -0,0 +1,292 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+// StreamingSamplesComparator is a memory-bounded alternative to
+// SamplesComparator for very large query_range responses. Rather than
+// fully unmarshalling data.result into a slice on both sides before
+// comparing anything, it walks data.result[*] entry-by-entry on both
+// sides with a jsoniter.Iterator, materializing only one series (or
+// vector sample, or stream) pair at a time.
+//
+// This assumes both responses list their results in the same
+// sorted-label order, which Loki's query_range/query handlers already
+// guarantee; a label mismatch at a given position is reported as a
+// missing metric rather than triggering the full fingerprint-indexed
+// realignment SamplesComparator does, since rebuilding that index would
+// mean buffering an entire side in memory again. It reports mismatches
+// under the same causes as SamplesComparator, so callers can swap between
+// the two under a config flag, but the summaries are not identical: each
+// series, sample or stream present only in the actual response gets its
+// own record rather than one count-mismatch record, and Skipped is set
+// when no position holds a result on both sides (which includes the case
+// where only one side is empty), not when every sample falls outside the
+// comparison window.
+type StreamingSamplesComparator struct {
+	opts SampleComparisonOptions
+}
+
+// NewStreamingSamplesComparator returns a ResponsesComparator as
+// described on StreamingSamplesComparator.
+func NewStreamingSamplesComparator(opts SampleComparisonOptions) *StreamingSamplesComparator {
+	return &StreamingSamplesComparator{opts: opts}
+}
+
+func (s *StreamingSamplesComparator) Compare(expectedResponse, actualResponse []byte, evaluationTime time.Time) (*ComparisonSummary, error) {
+	var expected, actual SamplesResponse
+
+	if err := json.Unmarshal(expectedResponse, &expected); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal expected response: %w", err)
+	}
+	if err := json.Unmarshal(actualResponse, &actual); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal actual response: %w", err)
+	}
+
+	report := newComparisonReport(s.opts.MaxMismatchesPerQuery)
+
+	if expected.Status != actual.Status {
+		report.add(MismatchRecord{Cause: CauseStatusMismatch, Message: fmt.Sprintf("expected status %s but got %s", expected.Status, actual.Status)})
+		return s.summary(report), report.err()
+	}
+	if expected.Data.ResultType != actual.Data.ResultType {
+		report.add(MismatchRecord{Cause: CauseResultTypeMismatch, Message: fmt.Sprintf("expected resultType %s but got %s", expected.Data.ResultType, actual.Data.ResultType)})
+		return s.summary(report), report.err()
+	}
+
+	if !s.opts.IgnoreAnnotations {
+		compareAnnotations(report, CauseWarningsMismatch, "warning", s.opts.filterAnnotations(expected.Warnings), s.opts.filterAnnotations(actual.Warnings))
+		compareAnnotations(report, CauseInfosMismatch, "info", s.opts.filterAnnotations(expected.Infos), s.opts.filterAnnotations(actual.Infos))
+	}
+
+	var (
+		skipped        bool
+		missingMetrics int
+		err            error
+	)
+	switch expected.Data.ResultType {
+	case "matrix":
+		skipped, missingMetrics, err = s.compareMatrix(expected.Data.Result, actual.Data.Result, evaluationTime, report)
+	case "vector":
+		skipped, missingMetrics, err = s.compareVector(expected.Data.Result, actual.Data.Result, evaluationTime, report)
+	case loghttp.ResultTypeStream:
+		skipped, missingMetrics, err = s.compareStreams(expected.Data.Result, actual.Data.Result, evaluationTime, report)
+	default:
+		return nil, fmt.Errorf("resultType %s not supported for streaming comparison", expected.Data.ResultType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summary := s.summary(report)
+	summary.Skipped = skipped
+	summary.MissingMetrics = missingMetrics
+	return summary, report.err()
+}
+
+func (s *StreamingSamplesComparator) summary(report *ComparisonReport) *ComparisonSummary {
+	return &ComparisonSummary{MismatchCause: report.firstCause(), Report: report}
+}
+
+func (s *StreamingSamplesComparator) compareMatrix(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, report *ComparisonReport) (skipped bool, missingMetrics int, err error) {
+	iterExpected := jsoniter.ParseBytes(jsoniter.ConfigDefault, expectedRaw)
+	iterActual := jsoniter.ParseBytes(jsoniter.ConfigDefault, actualRaw)
+
+	var any bool
+	missing := 0
+	for {
+		hasExpected := iterExpected.ReadArray()
+		hasActual := iterActual.ReadArray()
+		if !hasExpected && !hasActual {
+			break
+		}
+		if !hasExpected {
+			var extra comparableSeries
+			iterActual.ReadVal(&extra)
+			report.add(MismatchRecord{Cause: CauseMetricCountMismatch, Labels: extra.Metric.String(), Message: fmt.Sprintf("unexpected metric %s present only in actual response", extra.Metric)})
+			continue
+		}
+		if !hasActual {
+			var extra comparableSeries
+			iterExpected.ReadVal(&extra)
+			missing++
+			report.add(MismatchRecord{Cause: CauseMetricMissing, Labels: extra.Metric.String(), Message: fmt.Sprintf("expected metric %s missing from actual response", extra.Metric)})
+			continue
+		}
+
+		var expectedSeries, actualSeries comparableSeries
+		iterExpected.ReadVal(&expectedSeries)
+		iterActual.ReadVal(&actualSeries)
+		any = true
+
+		if expectedSeries.Metric.Fingerprint() != actualSeries.Metric.Fingerprint() {
+			missing++
+			report.add(MismatchRecord{Cause: CauseMetricMissing, Labels: expectedSeries.Metric.String(),
+				Message: fmt.Sprintf("expected metric %s at this position but got %s; responses may not be sorted identically", expectedSeries.Metric, actualSeries.Metric)})
+			continue
+		}
+
+		expectedSeries = filterSeriesOutsideWindowSingle(expectedSeries, evaluationTime, s.opts)
+		actualSeries = filterSeriesOutsideWindowSingle(actualSeries, evaluationTime, s.opts)
+		compareMatrixSamples(expectedSeries, actualSeries, s.opts, report)
+	}
+
+	if iterExpected.Error != nil && iterExpected.Error != io.EOF {
+		return false, 0, fmt.Errorf("decode expected matrix: %w", iterExpected.Error)
+	}
+	if iterActual.Error != nil && iterActual.Error != io.EOF {
+		return false, 0, fmt.Errorf("decode actual matrix: %w", iterActual.Error)
+	}
+
+	return !any, missing, nil
+}
+
+// filterSeriesOutsideWindowSingle applies the same sample-window
+// filtering as filterSamplesOutsideWindow to a single series, so the
+// streaming comparator can filter one series at a time without
+// buffering the rest of the matrix.
+func filterSeriesOutsideWindowSingle(series comparableSeries, evaluationTime time.Time, opts SampleComparisonOptions) comparableSeries {
+	if opts.SkipSamplesBefore.IsZero() && opts.SkipRecentSamples <= 0 {
+		return series
+	}
+	filtered := filterSamplesOutsideWindow([]comparableSeries{series}, func(t time.Time) bool {
+		return opts.SkipSample(t, evaluationTime)
+	})
+	if len(filtered) == 0 {
+		return comparableSeries{Metric: series.Metric}
+	}
+	return filtered[0]
+}
+
+func (s *StreamingSamplesComparator) compareVector(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, report *ComparisonReport) (skipped bool, missingMetrics int, err error) {
+	iterExpected := jsoniter.ParseBytes(jsoniter.ConfigDefault, expectedRaw)
+	iterActual := jsoniter.ParseBytes(jsoniter.ConfigDefault, actualRaw)
+
+	var any bool
+	missing := 0
+	for {
+		hasExpected := iterExpected.ReadArray()
+		hasActual := iterActual.ReadArray()
+		if !hasExpected && !hasActual {
+			break
+		}
+		if !hasExpected {
+			var extra comparableSample
+			iterActual.ReadVal(&extra)
+			report.add(MismatchRecord{Cause: CauseMetricCountMismatch, Labels: extra.Metric.String(), Message: fmt.Sprintf("unexpected metric %s present only in actual response", extra.Metric)})
+			continue
+		}
+		if !hasActual {
+			var extra comparableSample
+			iterExpected.ReadVal(&extra)
+			missing++
+			report.add(MismatchRecord{Cause: CauseMetricMissing, Labels: extra.Metric.String(), Message: fmt.Sprintf("expected metric %s missing from actual response", extra.Metric)})
+			continue
+		}
+
+		var expectedSample, actualSample comparableSample
+		iterExpected.ReadVal(&expectedSample)
+		iterActual.ReadVal(&actualSample)
+		any = true
+
+		if expectedSample.Metric.Fingerprint() != actualSample.Metric.Fingerprint() {
+			missing++
+			report.add(MismatchRecord{Cause: CauseMetricMissing, Labels: expectedSample.Metric.String(),
+				Message: fmt.Sprintf("expected metric %s at this position but got %s; responses may not be sorted identically", expectedSample.Metric, actualSample.Metric)})
+			continue
+		}
+
+		if !s.opts.SkipSamplesBefore.IsZero() || s.opts.SkipRecentSamples > 0 {
+			if s.opts.SkipSample(expectedSample.Timestamp.Time(), evaluationTime) && s.opts.SkipSample(actualSample.Timestamp.Time(), evaluationTime) {
+				continue
+			}
+		}
+
+		compareTypedSampleInto(expectedSample.Metric, expectedSample.typedSample(), actualSample.typedSample(), s.opts, report)
+	}
+
+	if iterExpected.Error != nil && iterExpected.Error != io.EOF {
+		return false, 0, fmt.Errorf("decode expected vector: %w", iterExpected.Error)
+	}
+	if iterActual.Error != nil && iterActual.Error != io.EOF {
+		return false, 0, fmt.Errorf("decode actual vector: %w", iterActual.Error)
+	}
+
+	return !any, missing, nil
+}
+
+func (s *StreamingSamplesComparator) compareStreams(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, report *ComparisonReport) (skipped bool, missingMetrics int, err error) {
+	iterExpected := jsoniter.ParseBytes(jsoniter.ConfigDefault, expectedRaw)
+	iterActual := jsoniter.ParseBytes(jsoniter.ConfigDefault, actualRaw)
+
+	var any bool
+	missing := 0
+	for {
+		hasExpected := iterExpected.ReadArray()
+		hasActual := iterActual.ReadArray()
+		if !hasExpected && !hasActual {
+			break
+		}
+		if !hasExpected {
+			var extra loghttp.Stream
+			iterActual.ReadVal(&extra)
+			report.add(MismatchRecord{Cause: CauseStreamCountMismatch, Labels: extra.Labels.String(), Message: fmt.Sprintf("unexpected stream %s present only in actual response", extra.Labels)})
+			continue
+		}
+		if !hasActual {
+			var extra loghttp.Stream
+			iterExpected.ReadVal(&extra)
+			missing++
+			report.add(MismatchRecord{Cause: CauseStreamMissing, Labels: extra.Labels.String(), Message: fmt.Sprintf("expected stream %s missing from actual response", extra.Labels)})
+			continue
+		}
+
+		var expectedStream, actualStream loghttp.Stream
+		iterExpected.ReadVal(&expectedStream)
+		iterActual.ReadVal(&actualStream)
+		any = true
+
+		if expectedStream.Labels.String() != actualStream.Labels.String() {
+			missing++
+			report.add(MismatchRecord{Cause: CauseStreamMissing, Labels: expectedStream.Labels.String(),
+				Message: fmt.Sprintf("expected stream %s at this position but got %s; responses may not be sorted identically", expectedStream.Labels, actualStream.Labels)})
+			continue
+		}
+
+		expectedStream.Entries = filterStreamEntriesWindow(expectedStream.Entries, evaluationTime, s.opts)
+		actualStream.Entries = filterStreamEntriesWindow(actualStream.Entries, evaluationTime, s.opts)
+		compareStreamEntries(expectedStream, actualStream, s.opts, report)
+	}
+
+	if iterExpected.Error != nil && iterExpected.Error != io.EOF {
+		return false, 0, fmt.Errorf("decode expected streams: %w", iterExpected.Error)
+	}
+	if iterActual.Error != nil && iterActual.Error != io.EOF {
+		return false, 0, fmt.Errorf("decode actual streams: %w", iterActual.Error)
+	}
+
+	return !any, missing, nil
+}
+
+// filterStreamEntriesWindow applies the same entry-window filtering as
+// filterStreamsOutsideWindow to a single stream's entries.
+func filterStreamEntriesWindow(entries []loghttp.Entry, evaluationTime time.Time, opts SampleComparisonOptions) []loghttp.Entry {
+	if opts.SkipSamplesBefore.IsZero() && opts.SkipRecentSamples <= 0 {
+		return entries
+	}
+	filtered := entries[:0]
+	for _, e := range entries {
+		if !opts.SkipSample(e.Timestamp, evaluationTime) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,48 @@
+package bos
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBucket_Integration exercises a real BOS bucket end to end. It is
+// skipped unless BOS_BUCKET is set; the credentials/endpoint env vars below
+// are read as they are and not checked, mirroring how Thanos gates its own objstore integration suites
+// behind a THANOS_TEST_OBJSTORE_SKIP-style presence check instead of
+// running them by default in CI.
+func TestBucket_Integration(t *testing.T) {
+	bucketName := os.Getenv("BOS_BUCKET")
+	if bucketName == "" {
+		t.Skip("BOS_BUCKET not set, skipping BOS integration test")
+	}
+
+	cfg := Config{
+		Bucket:          bucketName,
+		Endpoint:        os.Getenv("BOS_ENDPOINT"),
+		AccessKeyID:     os.Getenv("BOS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("BOS_SECRET_ACCESS_KEY"),
+	}
+
+	b, err := NewBucketClient(cfg, "bos-integration-test", log.NewNopLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	name := "loki-bos-integration-test-" + time.Now().UTC().Format(time.RFC3339)
+
+	require.NoError(t, b.Upload(ctx, name, strings.NewReader("hello")))
+	defer func() { _ = b.Delete(ctx, name) }()
+
+	exists, err := b.Exists(ctx, name)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	rc, err := b.Get(ctx, name)
+	require.NoError(t, err)
+	defer rc.Close()
+}
@@ -0,0 +1,209 @@
+// Package oci implements the pkg/storage/bucket.Provider contract for
+// Oracle Cloud Infrastructure (OCI) Object Storage, letting operators on
+// Oracle Cloud plug dataobj storage into their own object store without
+// forking the tree.
+package oci
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/loki/v3/pkg/storage/bucket"
+)
+
+// Config configures an OCI Object Storage bucket client.
+type Config struct {
+	Bucket        string `yaml:"bucket"`
+	Namespace     string `yaml:"namespace"`
+	CompartmentID string `yaml:"compartment_id"`
+	Region        string `yaml:"region"`
+	TenancyOCID   string `yaml:"tenancy_ocid"`
+	UserOCID      string `yaml:"user_ocid"`
+	Fingerprint   string `yaml:"fingerprint"`
+	PrivateKey    string `yaml:"private_key"`
+}
+
+// Bucket wraps an OCI Object Storage client behind the objstore.Bucket
+// interface.
+type Bucket struct {
+	name      string
+	bucket    string
+	namespace string
+	client    objectstorage.ObjectStorageClient
+}
+
+// NewBucketClient creates an OCI Object Storage bucket client for cfg.
+func NewBucketClient(cfg Config, name string, _ log.Logger) (*Bucket, error) {
+	provider := common.NewRawConfigurationProvider(cfg.TenancyOCID, cfg.UserOCID, cfg.Region, cfg.Fingerprint, cfg.PrivateKey, nil)
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	return &Bucket{name: name, bucket: cfg.Bucket, namespace: cfg.Namespace, client: client}, nil
+}
+
+// Name implements objstore.Bucket.
+func (b *Bucket) Name() string { return b.name }
+
+// Close implements objstore.Bucket. The OCI SDK client holds no resources
+// of its own that need releasing.
+func (b *Bucket) Close() error { return nil }
+
+// Upload implements objstore.Bucket.
+func (b *Bucket) Upload(ctx context.Context, name string, r io.Reader, _ ...objstore.ObjectUploadOption) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	size := int64(len(body))
+	_, err = b.client.PutObject(ctx, objectstorage.PutObjectRequest{
+		NamespaceName: &b.namespace,
+		BucketName:    &b.bucket,
+		ObjectName:    &name,
+		ContentLength: &size,
+		PutObjectBody: io.NopCloser(bytes.NewReader(body)),
+	})
+	return err
+}
+
+// Get implements objstore.Bucket.
+func (b *Bucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := b.client.GetObject(ctx, objectstorage.GetObjectRequest{
+		NamespaceName: &b.namespace,
+		BucketName:    &b.bucket,
+		ObjectName:    &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Content, nil
+}
+
+// GetRange implements objstore.Bucket.
+func (b *Bucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	rng := "bytes=" + strconv.FormatInt(off, 10) + "-" + strconv.FormatInt(off+length-1, 10)
+	resp, err := b.client.GetObject(ctx, objectstorage.GetObjectRequest{
+		NamespaceName: &b.namespace,
+		BucketName:    &b.bucket,
+		ObjectName:    &name,
+		Range:         &rng,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Content, nil
+}
+
+// Exists implements objstore.Bucket.
+func (b *Bucket) Exists(ctx context.Context, name string) (bool, error) {
+	_, err := b.client.HeadObject(ctx, objectstorage.HeadObjectRequest{
+		NamespaceName: &b.namespace,
+		BucketName:    &b.bucket,
+		ObjectName:    &name,
+	})
+	if err != nil {
+		if b.IsObjNotFoundErr(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Attributes implements objstore.Bucket.
+func (b *Bucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	resp, err := b.client.HeadObject(ctx, objectstorage.HeadObjectRequest{
+		NamespaceName: &b.namespace,
+		BucketName:    &b.bucket,
+		ObjectName:    &name,
+	})
+	if err != nil {
+		return objstore.ObjectAttributes{}, err
+	}
+	size := int64(0)
+	if resp.ContentLength != nil {
+		size = *resp.ContentLength
+	}
+	return objstore.ObjectAttributes{Size: size}, nil
+}
+
+// Delete implements objstore.Bucket.
+func (b *Bucket) Delete(ctx context.Context, name string) error {
+	_, err := b.client.DeleteObject(ctx, objectstorage.DeleteObjectRequest{
+		NamespaceName: &b.namespace,
+		BucketName:    &b.bucket,
+		ObjectName:    &name,
+	})
+	return err
+}
+
+// Iter implements objstore.Bucket.
+func (b *Bucket) Iter(ctx context.Context, dir string, f func(string) error, _ ...objstore.IterOption) error {
+	var start *string
+	for {
+		resp, err := b.client.ListObjects(ctx, objectstorage.ListObjectsRequest{
+			NamespaceName: &b.namespace,
+			BucketName:    &b.bucket,
+			Prefix:        &dir,
+			Delimiter:     common.String(objstore.DirDelim),
+			Start:         start,
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range resp.Prefixes {
+			if err := f(p); err != nil {
+				return err
+			}
+		}
+		for _, o := range resp.Objects {
+			if o.Name != nil {
+				if err := f(*o.Name); err != nil {
+					return err
+				}
+			}
+		}
+		if resp.NextStartWith == nil {
+			return nil
+		}
+		start = resp.NextStartWith
+	}
+}
+
+// IsObjNotFoundErr implements objstore.Bucket.
+func (b *Bucket) IsObjNotFoundErr(err error) bool {
+	svcErr, ok := common.IsServiceError(err)
+	return ok && svcErr.GetHTTPStatusCode() == 404
+}
+
+// IsAccessDeniedErr implements objstore.Bucket.
+func (b *Bucket) IsAccessDeniedErr(err error) bool {
+	svcErr, ok := common.IsServiceError(err)
+	return ok && svcErr.GetHTTPStatusCode() == 403
+}
+
+// provider adapts Bucket to the bucket.Provider registry contract.
+type provider struct{}
+
+func (provider) Name() string { return "OCI" }
+
+func (provider) NewBucket(_ context.Context, cfgYAML []byte, name string, logger log.Logger, _ prometheus.Registerer) (objstore.Bucket, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(cfgYAML, &cfg); err != nil {
+		return nil, err
+	}
+	return NewBucketClient(cfg, name, logger)
+}
+
+func init() {
+	bucket.Register(provider{})
+}
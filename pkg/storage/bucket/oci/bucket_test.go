@@ -0,0 +1,52 @@
+package oci
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBucket_Integration exercises a real OCI Object Storage bucket end to
+// end. It is skipped unless OCI_BUCKET is set; the auth env vars below are
+// read as they are and not checked, mirroring how Thanos gates its own objstore integration suites behind a
+// THANOS_TEST_OBJSTORE_SKIP-style presence check instead of running them by
+// default in CI.
+func TestBucket_Integration(t *testing.T) {
+	bucketName := os.Getenv("OCI_BUCKET")
+	if bucketName == "" {
+		t.Skip("OCI_BUCKET not set, skipping OCI integration test")
+	}
+
+	cfg := Config{
+		Bucket:        bucketName,
+		Namespace:     os.Getenv("OCI_NAMESPACE"),
+		CompartmentID: os.Getenv("OCI_COMPARTMENT_ID"),
+		Region:        os.Getenv("OCI_REGION"),
+		TenancyOCID:   os.Getenv("OCI_TENANCY_OCID"),
+		UserOCID:      os.Getenv("OCI_USER_OCID"),
+		Fingerprint:   os.Getenv("OCI_FINGERPRINT"),
+		PrivateKey:    os.Getenv("OCI_PRIVATE_KEY"),
+	}
+
+	b, err := NewBucketClient(cfg, "oci-integration-test", log.NewNopLogger())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	name := "loki-oci-integration-test-" + time.Now().UTC().Format(time.RFC3339)
+
+	require.NoError(t, b.Upload(ctx, name, strings.NewReader("hello")))
+	defer func() { _ = b.Delete(ctx, name) }()
+
+	exists, err := b.Exists(ctx, name)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	rc, err := b.Get(ctx, name)
+	require.NoError(t, err)
+	defer rc.Close()
+}
@@ -0,0 +1,67 @@
+// Package bucket provides a pluggable registry of object-storage backends
+// for Loki's dataobj storage, modeled after Thanos's objstore/client
+// factory: a Provider wraps one backend's vendor SDK behind the standard
+// objstore.Bucket interface and is looked up by name at construction time,
+// so adding a new backend doesn't require touching every caller that picks
+// one from a config blob.
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+)
+
+// Provider constructs an objstore.Bucket for one named object-storage
+// backend (e.g. "S3", "BOS", "OCI") from a Thanos-style YAML config blob.
+type Provider interface {
+	// Name is the backend name callers select with, matched
+	// case-insensitively by Register/Get/NewBucket.
+	Name() string
+	// NewBucket parses cfg as this provider's own Config type and builds
+	// the resulting client. name identifies the caller for metrics and
+	// logging, the same role it plays in the vendor SDK wrappers' existing
+	// NewBucketClient constructors.
+	NewBucket(ctx context.Context, cfg []byte, name string, logger log.Logger, reg prometheus.Registerer) (objstore.Bucket, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register adds p to the registry under its Name, matched
+// case-insensitively. Registering the same name twice replaces the
+// previous provider. Implementations typically call this from an init() in
+// their own package, e.g. pkg/storage/bucket/bos and
+// pkg/storage/bucket/oci, so importing that package for side effects is
+// enough to make its backend available to NewBucket.
+func Register(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[strings.ToUpper(p.Name())] = p
+}
+
+// Get returns the Provider registered under name, if any.
+func Get(name string) (Provider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[strings.ToUpper(name)]
+	return p, ok
+}
+
+// NewBucket looks up the Provider registered under providerName and builds
+// an objstore.Bucket from cfg through it, returning an error if no such
+// provider has been registered.
+func NewBucket(ctx context.Context, providerName string, cfg []byte, name string, logger log.Logger, reg prometheus.Registerer) (objstore.Bucket, error) {
+	p, ok := Get(providerName)
+	if !ok {
+		return nil, fmt.Errorf("unsupported object storage provider %q", providerName)
+	}
+	return p.NewBucket(ctx, cfg, name, logger, reg)
+}
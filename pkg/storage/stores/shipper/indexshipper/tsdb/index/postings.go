@@ -19,7 +19,10 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/cespare/xxhash/v2"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
 )
@@ -41,46 +44,140 @@ var ensureOrderBatchPool = sync.Pool{
 	},
 }
 
+// numPostingsShards is the number of shards MemPostings splits its label
+// names across. Each shard has its own lock, so Add, Get, Delete, Stats
+// and EnsureOrder calls touching different label names no longer
+// serialize behind a single mutex. 64 gives enough parallelism for
+// high-cardinality tenants without the per-shard bookkeeping becoming
+// noticeable next to the map overhead itself.
+const numPostingsShards = 64
+
+// postingsEntry is the value stored per (name, value) pair: a pointer that
+// is atomically swapped to publish a new, immutable backing slice. Once a
+// slice has been published through the pointer it is never mutated again --
+// addFor and Delete both build a fresh slice and CAS it in -- so any slice
+// obtained via Load (directly, or handed out through Get/Iter) remains a
+// valid, unchanging snapshot for as long as the reader holds it, even while
+// other goroutines keep publishing newer versions.
+type postingsEntry = atomic.Pointer[[]storage.SeriesRef]
+
+// postingsShard holds the postings lists for the subset of label names
+// routed to it. The shard's RWMutex only ever guards the shape of m -- i.e.
+// which (name, value) pairs exist -- never the contents of an individual
+// entry, which is managed lock-free through its postingsEntry pointer.
+type postingsShard struct {
+	mtx sync.RWMutex
+	m   map[string]map[string]*postingsEntry
+}
+
+func newPostingsShard() *postingsShard {
+	return &postingsShard{m: make(map[string]map[string]*postingsEntry, 512/numPostingsShards+1)}
+}
+
+// entryFor returns the entry for (name, value) if it already exists.
+func (sh *postingsShard) entryFor(name, value string) *postingsEntry {
+	sh.mtx.RLock()
+	defer sh.mtx.RUnlock()
+
+	nm, ok := sh.m[name]
+	if !ok {
+		return nil
+	}
+	return nm[value]
+}
+
+// ensureEntry returns the entry for (name, value), creating it (and the
+// name-keyed sub-map, if needed) under the shard's write lock if it doesn't
+// exist yet.
+func (sh *postingsShard) ensureEntry(name, value string) *postingsEntry {
+	sh.mtx.Lock()
+	defer sh.mtx.Unlock()
+
+	nm, ok := sh.m[name]
+	if !ok {
+		nm = map[string]*postingsEntry{}
+		sh.m[name] = nm
+	}
+	e, ok := nm[value]
+	if !ok {
+		e = &postingsEntry{}
+		nm[value] = e
+	}
+	return e
+}
+
 // MemPostings holds postings list for series ID per label pair. They may be written
 // to out of order.
 // EnsureOrder() must be called once before any reads are done. This allows for quick
 // unordered batch fills on startup.
+//
+// The label name space is split across numPostingsShards shards, each
+// guarded by its own RWMutex, so that reads and writes for unrelated
+// label names don't contend. allPostingsKey lives in its own dedicated
+// shard, since every Add touches it and it would otherwise serialize
+// writes behind whichever regular label name happens to hash to the
+// same shard.
 type MemPostings struct {
-	mtx     sync.RWMutex
-	m       map[string]map[string][]storage.SeriesRef
-	ordered bool
+	shards   [numPostingsShards]*postingsShard
+	allShard *postingsShard
+
+	orderedMtx sync.RWMutex
+	ordered    bool
+}
+
+func newMemPostings() *MemPostings {
+	p := &MemPostings{allShard: newPostingsShard()}
+	for i := range p.shards {
+		p.shards[i] = newPostingsShard()
+	}
+	return p
 }
 
 // NewMemPostings returns a memPostings that's ready for reads and writes.
 func NewMemPostings() *MemPostings {
-	return &MemPostings{
-		m:       make(map[string]map[string][]storage.SeriesRef, 512),
-		ordered: true,
-	}
+	p := newMemPostings()
+	p.ordered = true
+	return p
 }
 
 // NewUnorderedMemPostings returns a memPostings that is not safe to be read from
 // until EnsureOrder() was called once.
 func NewUnorderedMemPostings() *MemPostings {
-	return &MemPostings{
-		m:       make(map[string]map[string][]storage.SeriesRef, 512),
-		ordered: false,
+	return newMemPostings()
+}
+
+// shardFor returns the shard name's postings live in. allPostingsKey gets
+// its own dedicated shard so that its hot, every-Add write path never
+// contends with an unrelated label name that happens to hash the same.
+func (p *MemPostings) shardFor(name string) *postingsShard {
+	if name == allPostingsKey.Name {
+		return p.allShard
 	}
+	return p.shards[xxhash.Sum64String(name)%numPostingsShards]
+}
+
+// allShards returns every shard, including the dedicated all-postings
+// shard, for operations that must visit the whole label space.
+func (p *MemPostings) allShards() []*postingsShard {
+	all := make([]*postingsShard, 0, numPostingsShards+1)
+	all = append(all, p.allShard)
+	return append(all, p.shards[:]...)
 }
 
 // Symbols returns an iterator over all unique name and value strings, in order.
 func (p *MemPostings) Symbols() StringIter {
-	p.mtx.RLock()
-
 	// Add all the strings to a map to de-duplicate.
 	symbols := make(map[string]struct{}, 512)
-	for n, e := range p.m {
-		symbols[n] = struct{}{}
-		for v := range e {
-			symbols[v] = struct{}{}
+	for _, s := range p.allShards() {
+		s.mtx.RLock()
+		for n, e := range s.m {
+			symbols[n] = struct{}{}
+			for v := range e {
+				symbols[v] = struct{}{}
+			}
 		}
+		s.mtx.RUnlock()
 	}
-	p.mtx.RUnlock()
 
 	res := make([]string, 0, len(symbols))
 	for k := range symbols {
@@ -93,15 +190,17 @@ func (p *MemPostings) Symbols() StringIter {
 
 // SortedKeys returns a list of sorted label keys of the postings.
 func (p *MemPostings) SortedKeys() []labels.Label {
-	p.mtx.RLock()
-	keys := make([]labels.Label, 0, len(p.m))
+	var keys []labels.Label
 
-	for n, e := range p.m {
-		for v := range e {
-			keys = append(keys, labels.Label{Name: n, Value: v})
+	for _, s := range p.allShards() {
+		s.mtx.RLock()
+		for n, e := range s.m {
+			for v := range e {
+				keys = append(keys, labels.Label{Name: n, Value: v})
+			}
 		}
+		s.mtx.RUnlock()
 	}
-	p.mtx.RUnlock()
 
 	sort.Slice(keys, func(i, j int) bool {
 		if keys[i].Name != keys[j].Name {
@@ -114,34 +213,40 @@ func (p *MemPostings) SortedKeys() []labels.Label {
 
 // LabelNames returns all the unique label names.
 func (p *MemPostings) LabelNames() []string {
-	p.mtx.RLock()
-	defer p.mtx.RUnlock()
-	n := len(p.m)
-	if n == 0 {
-		return nil
-	}
-
-	names := make([]string, 0, n-1)
-	for name := range p.m {
-		if name != allPostingsKey.Name {
+	var names []string
+	for _, s := range p.shards {
+		s.mtx.RLock()
+		for name := range s.m {
 			names = append(names, name)
 		}
+		s.mtx.RUnlock()
 	}
 	return names
 }
 
 // LabelValues returns label values for the given name.
 func (p *MemPostings) LabelValues(name string) []string {
-	p.mtx.RLock()
-	defer p.mtx.RUnlock()
+	s := p.shardFor(name)
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
 
-	values := make([]string, 0, len(p.m[name]))
-	for v := range p.m[name] {
+	values := make([]string, 0, len(s.m[name]))
+	for v := range s.m[name] {
 		values = append(values, v)
 	}
 	return values
 }
 
+// entryLen returns the number of ids currently published through e, treating
+// a never-written entry as empty.
+func entryLen(e *postingsEntry) int {
+	l := e.Load()
+	if l == nil {
+		return 0
+	}
+	return len(*l)
+}
+
 // PostingsStats contains cardinality based statistics for postings.
 type PostingsStats struct {
 	CardinalityMetricsStats []Stat
@@ -151,12 +256,12 @@ type PostingsStats struct {
 	NumLabelPairs           int
 }
 
-// Stats calculates the cardinality statistics from postings.
+// Stats calculates the cardinality statistics from postings. Every shard
+// pushes into one shared set of top-K heaps, so the result is the global
+// top-K across all label names; the dedicated all-postings shard is not
+// scanned.
 func (p *MemPostings) Stats(label string) *PostingsStats {
 	const maxNumOfRecords = 10
-	var size uint64
-
-	p.mtx.RLock()
 
 	metrics := &maxHeap{}
 	labels := &maxHeap{}
@@ -169,25 +274,27 @@ func (p *MemPostings) Stats(label string) *PostingsStats {
 	labelValueLength.init(maxNumOfRecords)
 	labelValuePairs.init(maxNumOfRecords)
 
-	for n, e := range p.m {
-		if n == "" {
-			continue
-		}
-		labels.push(Stat{Name: n, Count: uint64(len(e))})
-		numLabelPairs += len(e)
-		size = 0
-		for name, values := range e {
-			if n == label {
-				metrics.push(Stat{Name: name, Count: uint64(len(values))})
+	for _, s := range p.shards {
+		var size uint64
+
+		s.mtx.RLock()
+		for n, e := range s.m {
+			labels.push(Stat{Name: n, Count: uint64(len(e))})
+			numLabelPairs += len(e)
+			size = 0
+			for name, entry := range e {
+				count := uint64(entryLen(entry))
+				if n == label {
+					metrics.push(Stat{Name: name, Count: count})
+				}
+				labelValuePairs.push(Stat{Name: n + "=" + name, Count: count})
+				size += uint64(len(name))
 			}
-			labelValuePairs.push(Stat{Name: n + "=" + name, Count: uint64(len(values))})
-			size += uint64(len(name))
+			labelValueLength.push(Stat{Name: n, Count: size})
 		}
-		labelValueLength.push(Stat{Name: n, Count: size})
+		s.mtx.RUnlock()
 	}
 
-	p.mtx.RUnlock()
-
 	return &PostingsStats{
 		CardinalityMetricsStats: metrics.get(),
 		CardinalityLabelStats:   labels.get(),
@@ -199,18 +306,20 @@ func (p *MemPostings) Stats(label string) *PostingsStats {
 
 // Get returns a postings list for the given label pair.
 func (p *MemPostings) Get(name, value string) Postings {
-	var lp []storage.SeriesRef
-	p.mtx.RLock()
-	l := p.m[name]
-	if l != nil {
-		lp = l[value]
+	s := p.shardFor(name)
+	e := s.entryFor(name, value)
+	if e == nil {
+		return EmptyPostings()
 	}
-	p.mtx.RUnlock()
 
+	// Load returns a pointer to an immutable, published slice: safe to hand
+	// straight to ListPostings without copying, and consistent even if
+	// Delete or addFor publish a newer version right after this Load.
+	lp := e.Load()
 	if lp == nil {
 		return EmptyPostings()
 	}
-	return newListPostings(lp...)
+	return newListPostings(*lp...)
 }
 
 // All returns a postings list over all documents ever added.
@@ -220,10 +329,33 @@ func (p *MemPostings) All() Postings {
 
 // EnsureOrder ensures that all postings lists are sorted. After it returns all further
 // calls to add and addFor will insert new IDs in a sorted manner.
+//
+// It locks every shard for the duration of the sort, same as the
+// unsharded implementation locked the single mutex for the whole
+// operation: EnsureOrder is only ever called once, before concurrent
+// access begins, so there's nothing to gain from finer-grained locking
+// here and it keeps the "all shards sorted, then flip ordered" sequence
+// easy to reason about.
 func (p *MemPostings) EnsureOrder() {
-	p.mtx.Lock()
-	defer p.mtx.Unlock()
+	p.orderedMtx.RLock()
+	ordered := p.ordered
+	p.orderedMtx.RUnlock()
+	if ordered {
+		return
+	}
 
+	shards := p.allShards()
+	for _, s := range shards {
+		s.mtx.Lock()
+	}
+	defer func() {
+		for _, s := range shards {
+			s.mtx.Unlock()
+		}
+	}()
+
+	p.orderedMtx.Lock()
+	defer p.orderedMtx.Unlock()
 	if p.ordered {
 		return
 	}
@@ -249,13 +381,23 @@ func (p *MemPostings) EnsureOrder() {
 	}
 
 	nextJob := ensureOrderBatchPool.Get().([][]storage.SeriesRef)
-	for _, e := range p.m {
-		for _, l := range e {
-			nextJob = append(nextJob, l)
-
-			if len(nextJob) >= ensureOrderBatchSize {
-				workc <- nextJob
-				nextJob = ensureOrderBatchPool.Get().([][]storage.SeriesRef)
+	for _, s := range shards {
+		for _, e := range s.m {
+			for _, entry := range e {
+				l := entry.Load()
+				if l == nil {
+					continue
+				}
+				// No concurrent readers can exist yet (EnsureOrder runs
+				// before reads are allowed), so it's safe to sort the
+				// published slice's backing array in place rather than
+				// publishing a new one.
+				nextJob = append(nextJob, *l)
+
+				if len(nextJob) >= ensureOrderBatchSize {
+					workc <- nextJob
+					nextJob = ensureOrderBatchPool.Get().([][]storage.SeriesRef)
+				}
 			}
 		}
 	}
@@ -271,73 +413,149 @@ func (p *MemPostings) EnsureOrder() {
 	p.ordered = true
 }
 
-// Delete removes all ids in the given map from the postings lists.
+// retiredPostings is published onto a postingsEntry's pointer, in place of
+// an empty slice, the moment deleteIDs commits to unlinking that entry from
+// its shard's map. Pointer identity (never its contents) is what matters:
+// once an entry holds exactly this pointer, it is retired for good, and
+// addFor must detect that and redirect to ensureEntry rather than CAS a new
+// list onto it -- otherwise a newly-added id could be published into an
+// entry object that deleteIDs has already (or is about to) drop from sh.m,
+// silently vanishing from every future Get/Iter/LabelValues.
+var retiredPostings = &[]storage.SeriesRef{}
+
+// Delete removes all ids in the given map from the postings lists. It works
+// against a copy-on-write snapshot: the set of affected entries is taken
+// under a brief read lock, the filtered replacement slice for each is
+// computed entirely lock-free, and publishing it back is a single CAS on
+// that entry's own pointer -- retried against the latest value if a
+// concurrent Add raced us -- rather than a shard-wide write lock. A large
+// retention/tombstone pass therefore no longer stalls ingestion into the
+// shard for its whole duration, only for the handful of CASes it actually
+// needs to perform.
 func (p *MemPostings) Delete(deleted map[storage.SeriesRef]struct{}) {
-	var keys, vals []string
-
-	// Collect all keys relevant for deletion once. New keys added afterwards
-	// can by definition not be affected by any of the given deletes.
-	p.mtx.RLock()
-	for n := range p.m {
-		keys = append(keys, n)
-	}
-	p.mtx.RUnlock()
-
-	for _, n := range keys {
-		p.mtx.RLock()
-		vals = vals[:0]
-		for v := range p.m[n] {
-			vals = append(vals, v)
-		}
-		p.mtx.RUnlock()
-
-		// For each posting we first analyse whether the postings list is affected by the deletes.
-		// If yes, we actually reallocate a new postings list.
-		for _, l := range vals {
-			// Only lock for processing one postings list so we don't block reads for too long.
-			p.mtx.Lock()
-
-			found := false
-			for _, id := range p.m[n][l] {
-				if _, ok := deleted[id]; ok {
-					found = true
-					break
-				}
-			}
-			if !found {
-				p.mtx.Unlock()
-				continue
-			}
-			repl := make([]storage.SeriesRef, 0, len(p.m[n][l]))
+	for _, s := range p.allShards() {
+		s.deleteIDs(deleted)
+	}
+}
 
-			for _, id := range p.m[n][l] {
-				if _, ok := deleted[id]; !ok {
-					repl = append(repl, id)
-				}
+// shardEntry identifies one (name, value) postings entry within a shard.
+type shardEntry struct {
+	name, value string
+	ptr         *postingsEntry
+}
+
+func (sh *postingsShard) deleteIDs(deleted map[storage.SeriesRef]struct{}) {
+	// Snapshot every entry pointer relevant to this shard once. New entries
+	// added afterwards can by definition not be affected by any of the
+	// given deletes, and pointer identity is stable for the entry's
+	// lifetime, so the snapshot stays valid while we work lock-free below.
+	sh.mtx.RLock()
+	entries := make([]shardEntry, 0, len(sh.m))
+	for n, e := range sh.m {
+		for v, ptr := range e {
+			entries = append(entries, shardEntry{name: n, value: v, ptr: ptr})
+		}
+	}
+	sh.mtx.RUnlock()
+
+	var retired []shardEntry
+	for _, se := range entries {
+		if casDeleteEntry(se.ptr, deleted) {
+			retired = append(retired, se)
+		}
+	}
+	if len(retired) == 0 {
+		return
+	}
+
+	// Unlink the now-retired entries from the map so tombstoned label
+	// values don't accumulate forever. This is safe even though the CAS
+	// that retired each entry happened outside this lock: retirement is
+	// final (casDeleteEntry never CASes away from retiredPostings), so a
+	// ptr still mapped to se.ptr here is guaranteed to be the same, dead
+	// entry we just retired, not one a concurrent Add resurrected.
+	sh.mtx.Lock()
+	for _, se := range retired {
+		nm, ok := sh.m[se.name]
+		if !ok {
+			continue
+		}
+		if ptr, ok := nm[se.value]; ok && ptr == se.ptr {
+			delete(nm, se.value)
+		}
+		if len(nm) == 0 {
+			delete(sh.m, se.name)
+		}
+	}
+	sh.mtx.Unlock()
+}
+
+// casDeleteEntry filters deleted out of e's currently published slice and
+// publishes the result, retrying against whatever a concurrent Add most
+// recently published if the CAS loses the race. If the filtered result is
+// empty, it additionally tries to retire the entry (CAS it from that empty
+// slice to retiredPostings) and reports whether that retirement succeeded.
+// A failed retirement CAS means a concurrent Add published a new, non-empty
+// list in between -- the entry is alive again and casDeleteEntry correctly
+// leaves it (and its map membership) alone.
+func casDeleteEntry(e *postingsEntry, deleted map[storage.SeriesRef]struct{}) (retired bool) {
+	for {
+		old := e.Load()
+		if old == nil || old == retiredPostings {
+			return false
+		}
+
+		found := false
+		for _, id := range *old {
+			if _, ok := deleted[id]; ok {
+				found = true
+				break
 			}
-			if len(repl) > 0 {
-				p.m[n][l] = repl
-			} else {
-				delete(p.m[n], l)
+		}
+		if !found {
+			return false
+		}
+
+		repl := make([]storage.SeriesRef, 0, len(*old))
+		for _, id := range *old {
+			if _, ok := deleted[id]; !ok {
+				repl = append(repl, id)
 			}
-			p.mtx.Unlock()
 		}
-		p.mtx.Lock()
-		if len(p.m[n]) == 0 {
-			delete(p.m, n)
+
+		if !e.CompareAndSwap(old, &repl) {
+			// A concurrent Add published a newer slice underneath us;
+			// recompute against it and retry.
+			continue
+		}
+		if len(repl) != 0 {
+			return false
 		}
-		p.mtx.Unlock()
+		return e.CompareAndSwap(&repl, retiredPostings)
 	}
 }
 
 // Iter calls f for each postings list. It aborts if f returns an error and returns it.
 func (p *MemPostings) Iter(f func(labels.Label, Postings) error) error {
-	p.mtx.RLock()
-	defer p.mtx.RUnlock()
+	for _, s := range p.allShards() {
+		if err := s.iter(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sh *postingsShard) iter(f func(labels.Label, Postings) error) error {
+	sh.mtx.RLock()
+	defer sh.mtx.RUnlock()
 
-	for n, e := range p.m {
-		for v, p := range e {
-			if err := f(labels.Label{Name: n, Value: v}, newListPostings(p...)); err != nil {
+	for n, e := range sh.m {
+		for v, ptr := range e {
+			l := ptr.Load()
+			if l == nil {
+				continue
+			}
+			if err := f(labels.Label{Name: n, Value: v}, newListPostings(*l...)); err != nil {
 				return err
 			}
 		}
@@ -347,37 +565,68 @@ func (p *MemPostings) Iter(f func(labels.Label, Postings) error) error {
 
 // Add a label set to the postings index.
 func (p *MemPostings) Add(id storage.SeriesRef, lset labels.Labels) {
-	p.mtx.Lock()
-
 	lset.Range(func(l labels.Label) {
 		p.addFor(id, l)
 	})
 	p.addFor(id, allPostingsKey)
-
-	p.mtx.Unlock()
 }
 
 func (p *MemPostings) addFor(id storage.SeriesRef, l labels.Label) {
-	nm, ok := p.m[l.Name]
-	if !ok {
-		nm = map[string][]storage.SeriesRef{}
-		p.m[l.Name] = nm
+	p.orderedMtx.RLock()
+	ordered := p.ordered
+	p.orderedMtx.RUnlock()
+
+	s := p.shardFor(l.Name)
+
+	// The shard's write lock is only needed the first time (name, value) is
+	// seen, to create its map entries; every subsequent append goes through
+	// the entry's own pointer lock-free.
+	e := s.entryFor(l.Name, l.Value)
+	if e == nil {
+		e = s.ensureEntry(l.Name, l.Value)
 	}
-	list := append(nm[l.Value], id)
-	nm[l.Value] = list
 
-	if !p.ordered {
-		return
-	}
-	// There is no guarantee that no higher ID was inserted before as they may
-	// be generated independently before adding them to postings.
-	// We repair order violations on insert. The invariant is that the first n-1
-	// items in the list are already sorted.
-	for i := len(list) - 1; i >= 1; i-- {
-		if list[i] >= list[i-1] {
-			break
+	for {
+		old := e.Load()
+		if old == retiredPostings {
+			// A concurrent Delete unlinked e from the shard map after we
+			// fetched it above; it must never be resurrected. Fetch (or
+			// recreate) the map's current, reachable entry and retry
+			// against that one instead.
+			e = s.ensureEntry(l.Name, l.Value)
+			continue
+		}
+		var oldList []storage.SeriesRef
+		if old != nil {
+			oldList = *old
 		}
-		list[i], list[i-1] = list[i-1], list[i]
+
+		// Published slices are immutable once visible through the entry's
+		// pointer (a concurrent Get/Delete may be reading oldList right
+		// now), so append must allocate a fresh backing array rather than
+		// growing oldList in place.
+		list := make([]storage.SeriesRef, len(oldList), len(oldList)+1)
+		copy(list, oldList)
+		list = append(list, id)
+
+		if ordered {
+			// There is no guarantee that no higher ID was inserted before as
+			// they may be generated independently before adding them to
+			// postings. We repair order violations on insert. The invariant
+			// is that the first n-1 items in the list are already sorted.
+			for i := len(list) - 1; i >= 1; i-- {
+				if list[i] >= list[i-1] {
+					break
+				}
+				list[i], list[i-1] = list[i-1], list[i]
+			}
+		}
+
+		if e.CompareAndSwap(old, &list) {
+			return
+		}
+		// A concurrent addFor (or Delete) published a newer slice
+		// underneath us; recompute the append against it and retry.
 	}
 }
 
@@ -436,6 +685,19 @@ func ErrPostings(err error) Postings {
 	return errPostings{err}
 }
 
+// Sized is implemented by Postings whose number of elements is known up
+// front without iterating, e.g. because they're backed by a plain in-memory
+// slice, byte stream, or bitmap. ListPostings, BigEndianPostings and
+// RoaringPostings all implement it, as does the iterator MemPostings.Get
+// returns, so callers building up boolean expressions over several
+// postings lists can pick a join order -- which side drives an Intersect,
+// whether Without is worth set-building -- without materializing anything.
+// Len returns -1 if the cardinality genuinely can't be known cheaply;
+// Intersect and Without treat that the same as "no hint".
+type Sized interface {
+	Len() int
+}
+
 // Intersect returns a new postings list over the intersection of the
 // input postings.
 func Intersect(its ...Postings) Postings {
@@ -451,9 +713,104 @@ func Intersect(its ...Postings) Postings {
 		}
 	}
 
-	return newIntersectPostings(its...)
+	if bms, ok := allRoaring(its); ok {
+		return newRoaringPostings(roaring64.FastAnd(bms...))
+	}
+
+	return newIntersectPostings(optimizeIntersectOrder(its)...)
+}
+
+// allRoaring returns the bitmaps backing its if every element is a
+// *RoaringPostings, letting Intersect/Merge/Without fast-path straight to
+// bitmap-level And/Or/AndNot instead of iterator-by-iterator merging.
+func allRoaring(its []Postings) ([]*roaring64.Bitmap, bool) {
+	bms := make([]*roaring64.Bitmap, len(its))
+	for i, p := range its {
+		rp, ok := p.(*RoaringPostings)
+		if !ok {
+			return nil, false
+		}
+		bms[i] = rp.bm
+	}
+	return bms, true
+}
+
+// sizedLen returns p's Sized.Len() hint, treating a missing Sized
+// implementation and an explicit -1 ("unknown") the same way: not ok.
+func sizedLen(p Postings) (int, bool) {
+	s, ok := p.(Sized)
+	if !ok {
+		return 0, false
+	}
+	n := s.Len()
+	if n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// optimizeIntersectOrder reorders its by ascending Sized.Len(), inputs
+// without a usable hint sort last, and wraps every input but the first
+// (cheapest/smallest) in LazyPostings. intersectPostings already evaluates
+// its arr in order and bails out the moment one member comes up empty, so
+// putting the precisely-sized, cheap inputs first means the expensive ones --
+// typically a Merge() of many matcher postings built from a regex
+// expansion -- are never even asked for their first value once a cheaper
+// input has already proven the intersection empty.
+func optimizeIntersectOrder(its []Postings) []Postings {
+	sorted := make([]Postings, len(its))
+	copy(sorted, its)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		li, oki := sizedLen(sorted[i])
+		lj, okj := sizedLen(sorted[j])
+		if !oki {
+			return false
+		}
+		if !okj {
+			return true
+		}
+		return li < lj
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		p := sorted[i]
+		sorted[i] = NewLazyPostings(func() Postings { return p })
+	}
+	return sorted
+}
+
+// LazyPostings wraps a constructor function and defers calling it until the
+// first call to Next, Seek, At or Err. It lets callers build a Postings up
+// front whose actual cost -- decoding a large postings list, expanding a
+// regex matcher against many label values, merging the results -- is only
+// paid if the postings are ever actually touched. Abandoning a LazyPostings
+// without ever calling one of those methods never invokes the constructor,
+// so it's safe to discard one unused: no goroutines started, no file
+// handles opened.
+type LazyPostings struct {
+	construct func() Postings
+	p         Postings
+}
+
+// NewLazyPostings returns a Postings that calls construct on its first use
+// and reuses the result for every subsequent call.
+func NewLazyPostings(construct func() Postings) *LazyPostings {
+	return &LazyPostings{construct: construct}
 }
 
+func (p *LazyPostings) resolve() Postings {
+	if p.p == nil {
+		p.p = p.construct()
+	}
+	return p.p
+}
+
+func (p *LazyPostings) Next() bool                    { return p.resolve().Next() }
+func (p *LazyPostings) Seek(v storage.SeriesRef) bool { return p.resolve().Seek(v) }
+func (p *LazyPostings) At() storage.SeriesRef         { return p.resolve().At() }
+func (p *LazyPostings) Err() error                    { return p.resolve().Err() }
+
 type intersectPostings struct {
 	arr []Postings
 	cur storage.SeriesRef
@@ -509,6 +866,15 @@ func (it *intersectPostings) Err() error {
 	return nil
 }
 
+// mergeLoserTreeThreshold is the number of input iterators above which
+// Merge uses a loser tree instead of the container/heap-based
+// mergedPostings. A regex matcher expanded against a high-cardinality
+// label can easily union hundreds of postings lists, where the loser
+// tree's O(log N) comparisons per Next() (with no heap-fix churn or
+// heap.Interface dispatch) pay for the extra bookkeeping; below the
+// threshold the heap's simplicity wins.
+const mergeLoserTreeThreshold = 8
+
 // Merge returns a new iterator over the union of the input iterators.
 func Merge(its ...Postings) Postings {
 	if len(its) == 0 {
@@ -518,6 +884,18 @@ func Merge(its ...Postings) Postings {
 		return its[0]
 	}
 
+	if bms, ok := allRoaring(its); ok {
+		return newRoaringPostings(roaring64.FastOr(bms...))
+	}
+
+	if len(its) > mergeLoserTreeThreshold {
+		p, ok := newLoserTreePostings(its)
+		if !ok {
+			return EmptyPostings()
+		}
+		return p
+	}
+
 	p, ok := newMergedPostings(its)
 	if !ok {
 		return EmptyPostings()
@@ -644,6 +1022,196 @@ func (it mergedPostings) Err() error {
 	return it.err
 }
 
+// loserTreeNone marks a loser-tree node as not yet holding any leaf, used
+// only while the tree is first being built.
+const loserTreeNone = -1
+
+// loserTreePostings is an N-way union over postings using a loser tree: a
+// complete binary tree with one leaf per input iterator and N-1 internal
+// nodes, each holding the index of the leaf that "lost" the match played
+// at that node, with the overall winner left at the root. Advancing the
+// current winner only replays the O(log N) matches on the path from its
+// leaf back to the root, rather than container/heap's sift-down, which
+// does a comparable number of comparisons but pays for it through
+// heap.Interface's Less/Swap indirection and re-heapifies the whole
+// affected subtree on every Pop/Fix.
+//
+// Exhausted iterators are treated as holding a value greater than any
+// real one, so they always lose and are never chosen as the winner again.
+type loserTreePostings struct {
+	its  []Postings
+	cur  []storage.SeriesRef
+	done []bool
+
+	tree []int // tree[0] is the current winner leaf; tree[1:] record the loser of each internal match.
+
+	curVal      storage.SeriesRef
+	initialized bool
+	err         error
+}
+
+func newLoserTreePostings(its []Postings) (t *loserTreePostings, nonEmpty bool) {
+	lt := &loserTreePostings{
+		its:  its,
+		cur:  make([]storage.SeriesRef, len(its)),
+		done: make([]bool, len(its)),
+		tree: make([]int, len(its)),
+	}
+	for i := range lt.tree {
+		lt.tree[i] = loserTreeNone
+	}
+
+	any := false
+	for i, it := range its {
+		// NOTE: loserTreePostings struct requires the user to issue an initial Next.
+		if it.Next() {
+			lt.cur[i] = it.At()
+			any = true
+		} else {
+			lt.done[i] = true
+			if it.Err() != nil {
+				return &loserTreePostings{err: it.Err()}, true
+			}
+		}
+	}
+	if !any {
+		return nil, false
+	}
+
+	for i := range its {
+		lt.play(i)
+	}
+	return lt, true
+}
+
+// less reports whether leaf a beats leaf b. An exhausted leaf never wins,
+// since it no longer has a real value to contribute.
+func (t *loserTreePostings) less(a, b int) bool {
+	if t.done[a] {
+		return false
+	}
+	if t.done[b] {
+		return true
+	}
+	return t.cur[a] < t.cur[b]
+}
+
+// play inserts leaf s into the tree, recording the loser of every match
+// played on the path to the root and leaving the winner in tree[0]. It is
+// used both to build the tree from scratch (called once per leaf, in
+// which case the first visit to an empty node just occupies it) and to
+// replay a single leaf after it advances (by then every node already
+// holds a leaf, so each visit plays a real match).
+func (t *loserTreePostings) play(s int) {
+	k := len(t.its)
+	parent := (s + k) / 2
+	for parent > 0 {
+		if t.tree[parent] == loserTreeNone {
+			t.tree[parent] = s
+			return
+		}
+		if !t.less(s, t.tree[parent]) {
+			s, t.tree[parent] = t.tree[parent], s
+		}
+		parent /= 2
+	}
+	t.tree[0] = s
+}
+
+func (t *loserTreePostings) At() storage.SeriesRef {
+	return t.curVal
+}
+
+func (t *loserTreePostings) Next() bool {
+	if t.err != nil {
+		return false
+	}
+
+	// The user must issue an initial Next.
+	if !t.initialized {
+		t.initialized = true
+		w := t.tree[0]
+		if t.done[w] {
+			return false
+		}
+		t.curVal = t.cur[w]
+		return true
+	}
+
+	for {
+		w := t.tree[0]
+		if t.done[w] {
+			return false
+		}
+
+		if t.its[w].Next() {
+			t.cur[w] = t.its[w].At()
+		} else {
+			t.done[w] = true
+			if t.its[w].Err() != nil {
+				t.err = t.its[w].Err()
+				return false
+			}
+		}
+		t.play(w)
+
+		w = t.tree[0]
+		if t.done[w] {
+			return false
+		}
+		if t.cur[w] != t.curVal {
+			t.curVal = t.cur[w]
+			return true
+		}
+	}
+}
+
+func (t *loserTreePostings) Seek(id storage.SeriesRef) bool {
+	if t.err != nil {
+		return false
+	}
+	if t.initialized && t.curVal >= id {
+		return true
+	}
+
+	for i, it := range t.its {
+		if t.done[i] || t.cur[i] >= id {
+			continue
+		}
+		if it.Seek(id) {
+			t.cur[i] = it.At()
+		} else {
+			t.done[i] = true
+			if it.Err() != nil {
+				t.err = it.Err()
+				return false
+			}
+		}
+	}
+
+	// Rebuild the tree from scratch rather than trying to patch the path
+	// for every leaf that moved: Seek can advance every leaf at once, so
+	// there's no single path left to replay.
+	for i := range t.tree {
+		t.tree[i] = loserTreeNone
+	}
+	for i := range t.its {
+		t.play(i)
+	}
+	t.initialized = true
+
+	w := t.tree[0]
+	if t.done[w] {
+		return false
+	}
+	t.curVal = t.cur[w]
+	return true
+}
+
+func (t *loserTreePostings) Err() error {
+	return t.err
+}
+
 // Without returns a new postings list that contains all elements from the full list that
 // are not in the drop list.
 func Without(full, drop Postings) Postings {
@@ -654,9 +1222,97 @@ func Without(full, drop Postings) Postings {
 	if drop == EmptyPostings() {
 		return full
 	}
+
+	if fullRP, ok := full.(*RoaringPostings); ok {
+		if dropRP, ok := drop.(*RoaringPostings); ok {
+			return newRoaringPostings(roaring64.AndNot(fullRP.bm, dropRP.bm))
+		}
+	}
+
+	if dropLen, ok := sizedLen(drop); ok {
+		fullLen, fullKnown := sizedLen(full)
+		if !fullKnown || fullLen > dropLen*withoutSetThresholdRatio {
+			return newWithoutSetPostings(full, drop, dropLen)
+		}
+	}
+
 	return newRemovedPostings(full, drop)
 }
 
+// withoutSetThresholdRatio is how many times smaller, by known cardinality,
+// drop must be than full before Without gathers drop into a set and scans
+// full once instead of merge-walking the two iterators together. Below the
+// ratio, the merge walk's O(1) extra state per comparison is cheaper than
+// paying to build and probe a set for every element of full.
+const withoutSetThresholdRatio = 20
+
+// withoutSetPostings implements Without by fully draining the (small) drop
+// side into a set up front, then streaming full once with O(1) set lookups.
+// Cheaper than removedPostings' merge walk when drop is much smaller than
+// full, since full is never made to wait on drop's own Seek/Next cost.
+type withoutSetPostings struct {
+	full    Postings
+	exclude map[storage.SeriesRef]struct{}
+
+	cur storage.SeriesRef
+	err error
+}
+
+func newWithoutSetPostings(full, drop Postings, dropLenHint int) *withoutSetPostings {
+	if dropLenHint < 0 {
+		dropLenHint = 0
+	}
+	wp := &withoutSetPostings{
+		full:    full,
+		exclude: make(map[storage.SeriesRef]struct{}, dropLenHint),
+	}
+	for drop.Next() {
+		wp.exclude[drop.At()] = struct{}{}
+	}
+	wp.err = drop.Err()
+	return wp
+}
+
+func (wp *withoutSetPostings) At() storage.SeriesRef {
+	return wp.cur
+}
+
+func (wp *withoutSetPostings) Next() bool {
+	if wp.err != nil {
+		return false
+	}
+	for wp.full.Next() {
+		if _, excluded := wp.exclude[wp.full.At()]; !excluded {
+			wp.cur = wp.full.At()
+			return true
+		}
+	}
+	wp.err = wp.full.Err()
+	return false
+}
+
+func (wp *withoutSetPostings) Seek(id storage.SeriesRef) bool {
+	if wp.err != nil {
+		return false
+	}
+	if wp.cur >= id {
+		return true
+	}
+	for wp.full.Seek(id) {
+		if _, excluded := wp.exclude[wp.full.At()]; !excluded {
+			wp.cur = wp.full.At()
+			return true
+		}
+		id = wp.full.At() + 1
+	}
+	wp.err = wp.full.Err()
+	return false
+}
+
+func (wp *withoutSetPostings) Err() error {
+	return wp.err
+}
+
 type removedPostings struct {
 	full, remove Postings
 
@@ -748,6 +1404,12 @@ func (it *ListPostings) At() storage.SeriesRef {
 	return it.cur
 }
 
+// Len returns the number of elements remaining in the list, satisfying
+// Sized so Intersect can use it as a cardinality hint.
+func (it *ListPostings) Len() int {
+	return len(it.list)
+}
+
 func (it *ListPostings) Next() bool {
 	if len(it.list) > 0 {
 		it.cur = it.list[0]
@@ -799,6 +1461,12 @@ func (it *BigEndianPostings) At() storage.SeriesRef {
 	return storage.SeriesRef(it.cur)
 }
 
+// Len returns the number of elements remaining in the stream, satisfying
+// Sized so Intersect can use it as a cardinality hint.
+func (it *BigEndianPostings) Len() int {
+	return len(it.list) / 4
+}
+
 func (it *BigEndianPostings) Next() bool {
 	if len(it.list) >= 4 {
 		it.cur = binary.BigEndian.Uint32(it.list)
@@ -832,6 +1500,99 @@ func (it *BigEndianPostings) Err() error {
 	return nil
 }
 
+// RoaringPostings implements the Postings interface over a compressed
+// roaring bitmap rather than a flat sorted array. It compresses dense
+// postings lists far better than ListPostings or BigEndianPostings and
+// makes repeated Seek cheap via the bitmap iterator's AdvanceIfNeeded.
+// Intersect, Merge and Without additionally recognize it as a fast path:
+// when every input is a *RoaringPostings they delegate straight to the
+// bitmap's And/Or/AndNot instead of merging iterator-by-iterator.
+type RoaringPostings struct {
+	bm  *roaring64.Bitmap
+	it  roaring64.IntPeekable64
+	cur storage.SeriesRef
+}
+
+// NewRoaringPostingsFromList returns a RoaringPostings containing list.
+func NewRoaringPostingsFromList(list []storage.SeriesRef) *RoaringPostings {
+	bm := roaring64.New()
+	for _, id := range list {
+		bm.Add(uint64(id))
+	}
+	return newRoaringPostings(bm)
+}
+
+func newRoaringPostings(bm *roaring64.Bitmap) *RoaringPostings {
+	return &RoaringPostings{bm: bm, it: bm.Iterator()}
+}
+
+// ToRoaring drains p into a roaring64.Bitmap. If p is already backed by a
+// roaring bitmap, that bitmap is cloned rather than re-added element by
+// element.
+func ToRoaring(p Postings) (*roaring64.Bitmap, error) {
+	if rp, ok := p.(*RoaringPostings); ok {
+		return rp.bm.Clone(), nil
+	}
+
+	bm := roaring64.New()
+	for p.Next() {
+		bm.Add(uint64(p.At()))
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return bm, nil
+}
+
+func (r *RoaringPostings) At() storage.SeriesRef {
+	return r.cur
+}
+
+func (r *RoaringPostings) Next() bool {
+	if !r.it.HasNext() {
+		return false
+	}
+	r.cur = storage.SeriesRef(r.it.Next())
+	return true
+}
+
+func (r *RoaringPostings) Seek(x storage.SeriesRef) bool {
+	if r.cur >= x {
+		return true
+	}
+	r.it.AdvanceIfNeeded(uint64(x))
+	return r.Next()
+}
+
+func (r *RoaringPostings) Err() error {
+	return nil
+}
+
+// Len returns the bitmap's cardinality, satisfying Sized so Intersect
+// can use it as a cardinality hint.
+func (r *RoaringPostings) Len() int {
+	return int(r.bm.GetCardinality())
+}
+
+// MarshalBinary serializes the underlying bitmap so a RoaringPostings can
+// be persisted in the index files.
+func (r *RoaringPostings) MarshalBinary() ([]byte, error) {
+	return r.bm.MarshalBinary()
+}
+
+// UnmarshalBinary replaces the bitmap with the one encoded in data,
+// resetting the iterator to the start.
+func (r *RoaringPostings) UnmarshalBinary(data []byte) error {
+	bm := roaring64.New()
+	if err := bm.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	r.bm = bm
+	r.it = bm.Iterator()
+	r.cur = 0
+	return nil
+}
+
 // seriesRefSlice attaches the methods of sort.Interface to []storage.SeriesRef, sorting in increasing order.
 type seriesRefSlice []storage.SeriesRef
 
@@ -0,0 +1,212 @@
+package index
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+)
+
+// TestMemPostings_GetConsistentDuringConcurrentDeleteAndAdd verifies that a
+// postings list returned by Get is a stable, self-consistent snapshot even
+// while Delete and Add are concurrently running against the same label
+// pair: Get must never observe a partially-updated (e.g. unsorted or
+// duplicated) slice, the symptom a torn read against the shared backing
+// array would produce.
+func TestMemPostings_GetConsistentDuringConcurrentDeleteAndAdd(t *testing.T) {
+	const initialIDs = 2000
+
+	mp := NewMemPostings()
+	lbl := labels.Label{Name: "job", Value: "x"}
+	for i := storage.SeriesRef(0); i < initialIDs; i++ {
+		mp.addFor(i, lbl)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Continuously delete and re-add a rotating id, racing with readers
+	// below.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		next := storage.SeriesRef(initialIDs)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			mp.Delete(map[storage.SeriesRef]struct{}{next % initialIDs: {}})
+			mp.addFor(next, lbl)
+			next++
+		}
+	}()
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				p := mp.Get(lbl.Name, lbl.Value)
+				var prev storage.SeriesRef
+				first := true
+				for p.Next() {
+					cur := p.At()
+					if !first && cur <= prev {
+						t.Errorf("postings list not strictly increasing: got %d after %d", cur, prev)
+					}
+					prev = cur
+					first = false
+				}
+				if err := p.Err(); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
+
+// BenchmarkIntersect_SmallAgainstLazyLarge intersects a small, empty-result
+// ListPostings (e.g. a selective matcher that happens to match nothing)
+// against a postings list wrapped in LazyPostings that simulates the cost
+// of expanding a regex matcher against many label values and merging the
+// results. intersectPostings.Next bails out on the first, small input
+// before ever advancing the rest of its arr, so the benchmark demonstrates
+// that the expensive constructor backing the LazyPostings is never invoked.
+func BenchmarkIntersect_SmallAgainstLazyLarge(b *testing.B) {
+	var small []storage.SeriesRef
+
+	const largeSize = 1_000_000
+	constructorCalls := 0
+	buildLarge := func() Postings {
+		constructorCalls++
+		large := make([]storage.SeriesRef, largeSize)
+		for i := range large {
+			large[i] = storage.SeriesRef(largeSize + i)
+		}
+		return newListPostings(large...)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := Intersect(newListPostings(small...), NewLazyPostings(buildLarge))
+		for p.Next() {
+		}
+		if err := p.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if constructorCalls != 0 {
+		b.Fatalf("expected the expensive postings constructor to never be invoked, got %d calls", constructorCalls)
+	}
+}
+
+// benchPostingsRange is the series ID space the density benchmarks below
+// sample from.
+const benchPostingsRange = 1 << 20
+
+// refsAtDensity returns a sorted slice of series refs covering roughly
+// density*benchPostingsRange of the ID space.
+func refsAtDensity(density float64, seed int64) []storage.SeriesRef {
+	rnd := rand.New(rand.NewSource(seed))
+	refs := make([]storage.SeriesRef, 0, int(float64(benchPostingsRange)*density))
+	for i := 0; i < benchPostingsRange; i++ {
+		if rnd.Float64() < density {
+			refs = append(refs, storage.SeriesRef(i))
+		}
+	}
+	return refs
+}
+
+func toBigEndianPostings(refs []storage.SeriesRef) *BigEndianPostings {
+	buf := make([]byte, len(refs)*4)
+	for i, r := range refs {
+		binary.BigEndian.PutUint32(buf[i*4:], uint32(r))
+	}
+	return NewBigEndianPostings(buf)
+}
+
+func drain(p Postings) {
+	for p.Next() {
+	}
+}
+
+// BenchmarkPostingsDensity compares RoaringPostings against BigEndianPostings
+// for Intersect, Merge and Without across low, medium and high density
+// postings lists.
+func BenchmarkPostingsDensity(b *testing.B) {
+	for _, density := range []float64{0.1, 0.5, 0.9} {
+		a := refsAtDensity(density, 1)
+		c := refsAtDensity(density, 2)
+
+		b.Run(fmt.Sprintf("Intersect/BigEndian/%.0f%%", density*100), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				drain(Intersect(toBigEndianPostings(a), toBigEndianPostings(c)))
+			}
+		})
+		b.Run(fmt.Sprintf("Intersect/Roaring/%.0f%%", density*100), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				drain(Intersect(NewRoaringPostingsFromList(a), NewRoaringPostingsFromList(c)))
+			}
+		})
+
+		b.Run(fmt.Sprintf("Merge/BigEndian/%.0f%%", density*100), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				drain(Merge(toBigEndianPostings(a), toBigEndianPostings(c)))
+			}
+		})
+		b.Run(fmt.Sprintf("Merge/Roaring/%.0f%%", density*100), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				drain(Merge(NewRoaringPostingsFromList(a), NewRoaringPostingsFromList(c)))
+			}
+		})
+
+		b.Run(fmt.Sprintf("Without/BigEndian/%.0f%%", density*100), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				drain(Without(toBigEndianPostings(a), toBigEndianPostings(c)))
+			}
+		})
+		b.Run(fmt.Sprintf("Without/Roaring/%.0f%%", density*100), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				drain(Without(NewRoaringPostingsFromList(a), NewRoaringPostingsFromList(c)))
+			}
+		})
+	}
+}
+
+// BenchmarkIntersect_HugeTiny is the pathological case a caller-dependent
+// join order used to be slow for: a huge postings list intersected with a
+// tiny one. Since Intersect now reorders its inputs by Sized.Len() before
+// running the merge, both argument orders below should perform the same --
+// driven by the tiny side -- rather than the 10x spread a naive
+// always-use-caller-order implementation produces when the huge side
+// happens to be passed first.
+func BenchmarkIntersect_HugeTiny(b *testing.B) {
+	huge := make([]storage.SeriesRef, 1_000_000)
+	for i := range huge {
+		huge[i] = storage.SeriesRef(i * 2)
+	}
+	tiny := []storage.SeriesRef{1, 3, 5, 7, 9}
+
+	b.Run("tiny-first", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			drain(Intersect(newListPostings(tiny...), newListPostings(huge...)))
+		}
+	})
+	b.Run("huge-first", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			drain(Intersect(newListPostings(huge...), newListPostings(tiny...)))
+		}
+	})
+}
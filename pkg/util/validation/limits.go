@@ -1,24 +1,267 @@
 package validation
 
 import (
+	"cmp"
 	"slices"
 	"time"
 )
 
+// Number constrains the built-in policies that need arithmetic (PolicySum,
+// PolicyMean, PolicyWeighted) to Go's numeric kinds, including named types
+// with a numeric underlying type such as time.Duration.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// TenantValue pairs a tenant ID with the value AggregatePerTenant extracted
+// for it, so a Policy can weigh or attribute its result back to a tenant
+// (e.g. PolicyWeighted) instead of seeing a bare, anonymous slice of values.
+type TenantValue[T any] struct {
+	TenantID string
+	Value    T
+}
+
+// Policy reduces the per-tenant values AggregatePerTenant extracted into a
+// single fleet-wide result of type R. Built-in policies below cover the
+// common cases (min/max/sum/mean/quantile/union/intersection/weighted); a
+// caller with a bespoke reducer can still implement Policy directly.
+type Policy[T any, R any] interface {
+	Reduce(values []TenantValue[T]) R
+}
+
+// PolicyFunc adapts a plain function to the Policy interface.
+type PolicyFunc[T any, R any] func(values []TenantValue[T]) R
+
+func (f PolicyFunc[T, R]) Reduce(values []TenantValue[T]) R { return f(values) }
+
+// AggregatePerTenant extracts one value per tenant via extract and reduces
+// them with policy. It replaces a collection of one-off per-tenant reducers
+// with a single generic entry point: callers express a fleet-wide behavior
+// (e.g. "cap at the P95 tenant's max_query_length") by picking or composing
+// a Policy instead of hand-rolling a new loop.
+func AggregatePerTenant[T any, R any](tenantIDs []string, extract func(string) T, policy Policy[T, R]) R {
+	values := make([]TenantValue[T], len(tenantIDs))
+	for i, tenantID := range tenantIDs {
+		values[i] = TenantValue[T]{TenantID: tenantID, Value: extract(tenantID)}
+	}
+	return policy.Reduce(values)
+}
+
+// PolicyMin returns the minimal value across all tenants, for any ordered
+// type. An empty tenant list returns the zero value of T.
+func PolicyMin[T cmp.Ordered]() Policy[T, T] {
+	return PolicyFunc[T, T](func(values []TenantValue[T]) T {
+		var zero T
+		var result *T
+		for _, tv := range values {
+			v := tv.Value
+			if result == nil || v < *result {
+				result = &v
+			}
+		}
+		if result == nil {
+			return zero
+		}
+		return *result
+	})
+}
+
+// PolicyMinNonZero returns the minimal positive, non-zero value across all
+// tenants. In many limits a value of 0 means unlimited, so this returns 0
+// only if every tenant has a limit of 0 (or the tenant list is empty).
+func PolicyMinNonZero[T cmp.Ordered]() Policy[T, T] {
+	return PolicyFunc[T, T](func(values []TenantValue[T]) T {
+		var zero T
+		var result *T
+		for _, tv := range values {
+			v := tv.Value
+			if v > zero && (result == nil || v < *result) {
+				result = &v
+			}
+		}
+		if result == nil {
+			return zero
+		}
+		return *result
+	})
+}
+
+// PolicyMax returns the maximal value across all tenants, for any ordered
+// type. An empty tenant list returns the zero value of T.
+func PolicyMax[T cmp.Ordered]() Policy[T, T] {
+	return PolicyFunc[T, T](func(values []TenantValue[T]) T {
+		var result T
+		for i, tv := range values {
+			v := tv.Value
+			if i == 0 || v > result {
+				result = v
+			}
+		}
+		return result
+	})
+}
+
+// PolicyMaxOrZero returns the maximal positive value across all tenants, or
+// the zero value of T as soon as any tenant's value is itself the zero
+// value -- i.e. "unlimited wins", mirroring how a 0 limit commonly means
+// unlimited.
+func PolicyMaxOrZero[T cmp.Ordered]() Policy[T, T] {
+	return PolicyFunc[T, T](func(values []TenantValue[T]) T {
+		var zero T
+		var result *T
+		for _, tv := range values {
+			v := tv.Value
+			if v == zero {
+				return zero
+			}
+			if v > zero && (result == nil || v > *result) {
+				result = &v
+			}
+		}
+		if result == nil {
+			return zero
+		}
+		return *result
+	})
+}
+
+// PolicySum returns the sum of all tenants' values.
+func PolicySum[T Number]() Policy[T, T] {
+	return PolicyFunc[T, T](func(values []TenantValue[T]) T {
+		var sum T
+		for _, tv := range values {
+			sum += tv.Value
+		}
+		return sum
+	})
+}
+
+// PolicyMean returns the arithmetic mean of all tenants' values, truncated
+// to T. An empty tenant list returns the zero value of T.
+func PolicyMean[T Number]() Policy[T, T] {
+	return PolicyFunc[T, T](func(values []TenantValue[T]) T {
+		var zero T
+		if len(values) == 0 {
+			return zero
+		}
+		var sum T
+		for _, tv := range values {
+			sum += tv.Value
+		}
+		return sum / T(len(values))
+	})
+}
+
+// PolicyQuantile returns the value at the given quantile (0-1) of all
+// tenants' values: the element at index int(quantile*n) of the n sorted
+// values, so 0.5 over 1, 2, 3, 4 gives 3. This generalizes the
+// min/max policies to percentile-based behaviors, e.g. capping at the p95
+// tenant's limit rather than the strict max. An empty tenant list returns
+// the zero value of T.
+func PolicyQuantile[T cmp.Ordered](quantile float64) Policy[T, T] {
+	return PolicyFunc[T, T](func(values []TenantValue[T]) T {
+		var zero T
+		if len(values) == 0 {
+			return zero
+		}
+
+		sorted := make([]T, len(values))
+		for i, tv := range values {
+			sorted[i] = tv.Value
+		}
+		slices.Sort(sorted)
+
+		if quantile <= 0 {
+			return sorted[0]
+		}
+		if quantile >= 1 {
+			return sorted[len(sorted)-1]
+		}
+
+		rank := int(quantile * float64(len(sorted)))
+		if rank >= len(sorted) {
+			rank = len(sorted) - 1
+		}
+		return sorted[rank]
+	})
+}
+
+// PolicyUnion returns the sorted union of all tenants' string sets.
+func PolicyUnion() Policy[[]string, []string] {
+	return PolicyFunc[[]string, []string](func(values []TenantValue[[]string]) []string {
+		seen := map[string]struct{}{}
+		for _, tv := range values {
+			for _, s := range tv.Value {
+				seen[s] = struct{}{}
+			}
+		}
+		result := make([]string, 0, len(seen))
+		for s := range seen {
+			result = append(result, s)
+		}
+		slices.Sort(result)
+		return result
+	})
+}
+
+// PolicyIntersection returns the sorted intersection of all tenants' string
+// sets. This is useful to determine the minimal feature set supported
+// across a set of tenants.
+func PolicyIntersection() Policy[[]string, []string] {
+	return PolicyFunc[[]string, []string](func(values []TenantValue[[]string]) []string {
+		var result []string
+		for _, tv := range values {
+			v := slices.Clone(tv.Value)
+			slices.Sort(v)
+			if result == nil {
+				result = v
+				continue
+			}
+			var updatedResult []string
+			for i, j := 0, 0; i < len(result) && j < len(v); {
+				if result[i] == v[j] {
+					updatedResult = append(updatedResult, result[i])
+					i++
+					j++
+				} else if result[i] < v[j] {
+					i++
+				} else {
+					j++
+				}
+			}
+			result = updatedResult
+		}
+		return result
+	})
+}
+
+// PolicyWeighted returns the weighted average of all tenants' values,
+// weighted by weights(tenantID). Tenants with zero or negative weight don't
+// contribute. If the total weight is 0, PolicyWeighted returns 0.
+func PolicyWeighted[T Number](weights func(tenantID string) float64) Policy[T, float64] {
+	return PolicyFunc[T, float64](func(values []TenantValue[T]) float64 {
+		var weightedSum, totalWeight float64
+		for _, tv := range values {
+			w := weights(tv.TenantID)
+			if w <= 0 {
+				continue
+			}
+			weightedSum += float64(tv.Value) * w
+			totalWeight += w
+		}
+		if totalWeight == 0 {
+			return 0
+		}
+		return weightedSum / totalWeight
+	})
+}
+
 // SmallestPositiveIntPerTenant is returning the minimal positive value of the
 // supplied limit function for all given tenants.
 func SmallestPositiveIntPerTenant(tenantIDs []string, f func(string) int) int {
-	var result *int
-	for _, tenantID := range tenantIDs {
-		v := f(tenantID)
-		if result == nil || v < *result {
-			result = &v
-		}
-	}
-	if result == nil {
-		return 0
-	}
-	return *result
+	return AggregatePerTenant(tenantIDs, f, PolicyMin[int]())
 }
 
 // SmallestPositiveNonZeroIntPerTenant is returning the minimal positive and
@@ -26,17 +269,7 @@ func SmallestPositiveIntPerTenant(tenantIDs []string, f func(string) int) int {
 // limits a value of 0 means unlimited so the method will return 0 only if all
 // inputs have a limit of 0 or an empty tenant list is given.
 func SmallestPositiveNonZeroIntPerTenant(tenantIDs []string, f func(string) int) int {
-	var result *int
-	for _, tenantID := range tenantIDs {
-		v := f(tenantID)
-		if v > 0 && (result == nil || v < *result) {
-			result = &v
-		}
-	}
-	if result == nil {
-		return 0
-	}
-	return *result
+	return AggregatePerTenant(tenantIDs, f, PolicyMinNonZero[int]())
 }
 
 // SmallestPositiveNonZeroDurationPerTenant is returning the minimal positive
@@ -44,75 +277,54 @@ func SmallestPositiveNonZeroIntPerTenant(tenantIDs []string, f func(string) int)
 // many limits a value of 0 means unlimited so the method will return 0 only if
 // all inputs have a limit of 0 or an empty tenant list is given.
 func SmallestPositiveNonZeroDurationPerTenant(tenantIDs []string, f func(string) time.Duration) time.Duration {
-	var result *time.Duration
-	for _, tenantID := range tenantIDs {
-		v := f(tenantID)
-		if v > 0 && (result == nil || v < *result) {
-			result = &v
-		}
-	}
-	if result == nil {
-		return 0
-	}
-	return *result
+	return AggregatePerTenant(tenantIDs, f, PolicyMinNonZero[time.Duration]())
 }
 
 // MaxDurationPerTenant is returning the maximum duration per tenant. Without
 // tenants given it will return a time.Duration(0).
 func MaxDurationPerTenant(tenantIDs []string, f func(string) time.Duration) time.Duration {
-	result := time.Duration(0)
-	for _, tenantID := range tenantIDs {
-		v := f(tenantID)
-		if v > result {
-			result = v
-		}
+	result := AggregatePerTenant(tenantIDs, f, PolicyMax[time.Duration]())
+	if result < 0 {
+		return 0
 	}
 	return result
 }
 
 // MaxDurationOrZeroPerTenant is returning the maximum duration per tenant or zero if one tenant has time.Duration(0).
 func MaxDurationOrZeroPerTenant(tenantIDs []string, f func(string) time.Duration) time.Duration {
-	var result *time.Duration
-	for _, tenantID := range tenantIDs {
-		v := f(tenantID)
-		if v == 0 {
-			return v
-		}
+	return AggregatePerTenant(tenantIDs, f, PolicyMaxOrZero[time.Duration]())
+}
 
-		if v > 0 && (result == nil || v > *result) {
-			result = &v
-		}
-	}
-	if result == nil {
-		return 0
-	}
-	return *result
+// SmallestPositivePerTenant is the typed generalization of
+// SmallestPositiveIntPerTenant: it returns the minimal value of the
+// supplied limit function across all given tenants, for any ordered type,
+// using PolicyMin. Unlike SmallestPositiveNonZeroDurationPerTenant it does
+// not skip zero or negative values. An empty tenant list returns the zero
+// value of T.
+func SmallestPositivePerTenant[T cmp.Ordered](tenantIDs []string, f func(string) T) T {
+	return AggregatePerTenant(tenantIDs, f, PolicyMin[T]())
+}
+
+// LargestPerTenant is the typed generalization of MaxDurationPerTenant: it
+// returns the maximum value of the supplied limit function across all given
+// tenants, for any ordered type. An empty tenant list returns the zero value
+// of T.
+func LargestPerTenant[T cmp.Ordered](tenantIDs []string, f func(string) T) T {
+	return AggregatePerTenant(tenantIDs, f, PolicyMax[T]())
+}
+
+// QuantilePerTenant returns the value at the given quantile (0-1) of the
+// supplied limit function across all given tenants, indexed the same way as
+// PolicyQuantile. This generalizes the smallest/largest aggregators to
+// percentile-based policies, e.g. provisioning a shared resource for the
+// p90 of per-tenant limits rather than the strict max. An empty tenant list
+// returns the zero value of T.
+func QuantilePerTenant[T cmp.Ordered](tenantIDs []string, quantile float64, f func(string) T) T {
+	return AggregatePerTenant(tenantIDs, f, PolicyQuantile[T](quantile))
 }
 
 // IntersectionPerTenant is returning the intersection of feature flags. This is useful to determine the minimal
 // feature set supported.
 func IntersectionPerTenant(tenantIDs []string, f func(string) []string) []string {
-	var result []string
-	for _, tenantID := range tenantIDs {
-		v := f(tenantID)
-		slices.Sort(v)
-		if result == nil {
-			result = v
-			continue
-		}
-		var updatedResult []string
-		for i, j := 0, 0; i < len(result) && j < len(v); {
-			if result[i] == v[j] {
-				updatedResult = append(updatedResult, result[i])
-				i++
-				j++
-			} else if result[i] < v[j] {
-				i++
-			} else {
-				j++
-			}
-		}
-		result = updatedResult
-	}
-	return result
+	return AggregatePerTenant(tenantIDs, f, PolicyIntersection())
 }
@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregatePerTenantExtractsThenReduces(t *testing.T) {
+	tenantIDs := []string{"a", "b", "c"}
+	limits := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	got := AggregatePerTenant(tenantIDs, func(id string) int { return limits[id] }, PolicySum[int]())
+	require.Equal(t, 6, got)
+}
+
+func TestPolicyMin(t *testing.T) {
+	require.Equal(t, 0, PolicyMin[int]().Reduce(nil))
+	require.Equal(t, 1, PolicyMin[int]().Reduce([]TenantValue[int]{{Value: 3}, {Value: 1}, {Value: 2}}))
+}
+
+func TestPolicyMinNonZeroIgnoresZeroUnlessAllZero(t *testing.T) {
+	require.Equal(t, 2, PolicyMinNonZero[int]().Reduce([]TenantValue[int]{{Value: 0}, {Value: 5}, {Value: 2}}))
+	require.Equal(t, 0, PolicyMinNonZero[int]().Reduce([]TenantValue[int]{{Value: 0}, {Value: 0}}))
+}
+
+func TestPolicyMax(t *testing.T) {
+	require.Equal(t, 0, PolicyMax[int]().Reduce(nil))
+	require.Equal(t, 3, PolicyMax[int]().Reduce([]TenantValue[int]{{Value: 1}, {Value: 3}, {Value: 2}}))
+}
+
+func TestPolicyMaxOrZeroReturnsZeroIfAnyTenantIsZero(t *testing.T) {
+	require.Equal(t, 0, PolicyMaxOrZero[int]().Reduce([]TenantValue[int]{{Value: 5}, {Value: 0}, {Value: 3}}))
+	require.Equal(t, 5, PolicyMaxOrZero[int]().Reduce([]TenantValue[int]{{Value: 5}, {Value: 3}}))
+}
+
+func TestPolicySum(t *testing.T) {
+	require.Equal(t, 6, PolicySum[int]().Reduce([]TenantValue[int]{{Value: 1}, {Value: 2}, {Value: 3}}))
+}
+
+func TestPolicyMean(t *testing.T) {
+	require.Equal(t, 0, PolicyMean[int]().Reduce(nil))
+	require.Equal(t, 2, PolicyMean[int]().Reduce([]TenantValue[int]{{Value: 1}, {Value: 2}, {Value: 3}}))
+}
+
+func TestPolicyQuantile(t *testing.T) {
+	values := []TenantValue[int]{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}}
+	require.Equal(t, 0, PolicyQuantile[int](0.5).Reduce(nil))
+	require.Equal(t, 1, PolicyQuantile[int](0).Reduce(values))
+	require.Equal(t, 4, PolicyQuantile[int](1).Reduce(values))
+	require.Equal(t, 3, PolicyQuantile[int](0.5).Reduce(values))
+}
+
+func TestPolicyUnion(t *testing.T) {
+	got := PolicyUnion().Reduce([]TenantValue[[]string]{
+		{Value: []string{"b", "a"}},
+		{Value: []string{"c", "a"}},
+	})
+	require.Equal(t, []string{"a", "b", "c"}, got)
+}
+
+func TestPolicyIntersection(t *testing.T) {
+	got := PolicyIntersection().Reduce([]TenantValue[[]string]{
+		{Value: []string{"a", "b", "c"}},
+		{Value: []string{"b", "c", "d"}},
+		{Value: []string{"b", "c"}},
+	})
+	require.Equal(t, []string{"b", "c"}, got)
+}
+
+func TestPolicyWeightedIgnoresNonPositiveWeightsAndHandlesZeroTotal(t *testing.T) {
+	weights := map[string]float64{"a": 1, "b": -1, "c": 0}
+	policy := PolicyWeighted[int](func(id string) float64 { return weights[id] })
+
+	got := policy.Reduce([]TenantValue[int]{
+		{TenantID: "a", Value: 10},
+		{TenantID: "b", Value: 100},
+		{TenantID: "c", Value: 100},
+	})
+	require.Equal(t, 10.0, got)
+
+	require.Equal(t, 0.0, policy.Reduce([]TenantValue[int]{{TenantID: "b", Value: 5}}))
+}
+
+func TestSmallestPositiveIntPerTenant(t *testing.T) {
+	limits := map[string]int{"a": 3, "b": 1}
+	got := SmallestPositiveIntPerTenant([]string{"a", "b"}, func(id string) int { return limits[id] })
+	require.Equal(t, 1, got)
+}
+
+func TestSmallestPositiveNonZeroDurationPerTenant(t *testing.T) {
+	limits := map[string]time.Duration{"a": 0, "b": 5 * time.Second}
+	got := SmallestPositiveNonZeroDurationPerTenant([]string{"a", "b"}, func(id string) time.Duration { return limits[id] })
+	require.Equal(t, 5*time.Second, got)
+}
+
+func TestMaxDurationPerTenantClampsNegativeToZero(t *testing.T) {
+	limits := map[string]time.Duration{"a": -5 * time.Second}
+	got := MaxDurationPerTenant([]string{"a"}, func(id string) time.Duration { return limits[id] })
+	require.Equal(t, time.Duration(0), got)
+}
+
+func TestMaxDurationOrZeroPerTenant(t *testing.T) {
+	limits := map[string]time.Duration{"a": 5 * time.Second, "b": 0}
+	got := MaxDurationOrZeroPerTenant([]string{"a", "b"}, func(id string) time.Duration { return limits[id] })
+	require.Equal(t, time.Duration(0), got)
+}
+
+func TestQuantilePerTenant(t *testing.T) {
+	limits := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+	got := QuantilePerTenant([]string{"a", "b", "c", "d"}, 0.5, func(id string) int { return limits[id] })
+	require.Equal(t, 3, got)
+}
+
+func TestIntersectionPerTenant(t *testing.T) {
+	features := map[string][]string{"a": {"x", "y"}, "b": {"y", "z"}}
+	got := IntersectionPerTenant([]string{"a", "b"}, func(id string) []string { return features[id] })
+	require.Equal(t, []string{"y"}, got)
+}
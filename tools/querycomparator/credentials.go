@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// S3CredentialMode selects how MustS3DataobjBucket should obtain AWS
+// credentials, instead of the caller hard-coding a static access key,
+// secret, and session token.
+type S3CredentialMode string
+
+const (
+	// S3CredentialModeChain resolves credentials through the standard
+	// env/shared-config/EC2-IMDS chain, i.e. whatever aws-sdk-go-v2's
+	// config.LoadDefaultConfig would pick up on its own.
+	S3CredentialModeChain S3CredentialMode = "chain"
+	// S3CredentialModeWebIdentity resolves credentials via a web identity
+	// token, the mechanism EKS uses to project IAM Roles for Service
+	// Accounts (IRSA) into a pod.
+	S3CredentialModeWebIdentity S3CredentialMode = "web_identity"
+	// S3CredentialModeAssumeRole resolves credentials by calling STS
+	// AssumeRole and refreshes them automatically as they near expiry.
+	S3CredentialModeAssumeRole S3CredentialMode = "assume_role"
+)
+
+// S3CredentialConfig configures how NewS3CredentialsProvider resolves AWS
+// credentials for the dataobj S3 bucket. Only the fields relevant to Mode
+// need to be set.
+type S3CredentialConfig struct {
+	Mode S3CredentialMode
+
+	// RoleARN and SessionName are required for S3CredentialModeAssumeRole,
+	// and RoleARN is required for S3CredentialModeWebIdentity unless
+	// AWS_ROLE_ARN is already set in the environment.
+	RoleARN     string
+	SessionName string
+	// ExternalID is passed through to STS AssumeRole when set, for
+	// cross-account trust policies that require it.
+	ExternalID string
+
+	// SessionTokenFile overrides the on-disk path of the rotating identity
+	// token used for S3CredentialModeWebIdentity, e.g. a Kubernetes
+	// projected service account token mounted somewhere other than the
+	// path AWS_WEB_IDENTITY_TOKEN_FILE already points at. When empty,
+	// AWS_WEB_IDENTITY_TOKEN_FILE is used, matching the SDK's own default
+	// chain.
+	SessionTokenFile string
+}
+
+// NewS3CredentialsProvider builds the aws.CredentialsProvider
+// MustS3DataobjBucket should use for cfg.Mode. The returned provider is
+// wrapped in an aws.CredentialsCache, so callers can call Retrieve on every
+// request without repeating a network round trip until the cached
+// credentials approach expiry -- the same pattern aws-sdk-go-v2's own
+// default chain uses, and what lets MustS3DataobjBucket honor rotating STS
+// or IRSA creds without ever needing a restart.
+func NewS3CredentialsProvider(ctx context.Context, cfg S3CredentialConfig) (aws.CredentialsProvider, error) {
+	switch cfg.Mode {
+	case S3CredentialModeWebIdentity:
+		return webIdentityCredentials(ctx, cfg)
+	case S3CredentialModeAssumeRole:
+		return assumeRoleCredentials(ctx, cfg)
+	case S3CredentialModeChain, "":
+		return defaultCredentialChain(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported S3 credential mode: %q", cfg.Mode)
+	}
+}
+
+// defaultCredentialChain resolves credentials through the standard
+// env/shared-config/EC2-IMDS chain.
+func defaultCredentialChain(ctx context.Context) (aws.CredentialsProvider, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load default AWS config: %w", err)
+	}
+	return awsCfg.Credentials, nil
+}
+
+// webIdentityCredentials resolves credentials via STS AssumeRoleWithWebIdentity,
+// the mechanism EKS IRSA uses to inject a rotating token into a pod.
+func webIdentityCredentials(ctx context.Context, cfg S3CredentialConfig) (aws.CredentialsProvider, error) {
+	roleARN := cfg.RoleARN
+	if roleARN == "" {
+		roleARN = os.Getenv("AWS_ROLE_ARN")
+	}
+	tokenFile := cfg.SessionTokenFile
+	if tokenFile == "" {
+		tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+	if roleARN == "" || tokenFile == "" {
+		return nil, fmt.Errorf("web identity credentials require a role ARN and a token file, got role ARN %q and token file %q", roleARN, tokenFile)
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load default AWS config: %w", err)
+	}
+	client := sts.NewFromConfig(awsCfg)
+	provider := stscreds.NewWebIdentityRoleProvider(client, roleARN, stscreds.IdentityTokenFile(tokenFile))
+	return aws.NewCredentialsCache(provider), nil
+}
+
+// assumeRoleCredentials resolves credentials by calling STS AssumeRole, and
+// transparently re-assumes the role as the session token approaches expiry.
+func assumeRoleCredentials(ctx context.Context, cfg S3CredentialConfig) (aws.CredentialsProvider, error) {
+	if cfg.RoleARN == "" || cfg.SessionName == "" {
+		return nil, fmt.Errorf("assume role credentials require a role ARN and session name")
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load default AWS config: %w", err)
+	}
+	client := sts.NewFromConfig(awsCfg)
+	provider := stscreds.NewAssumeRoleProvider(client, cfg.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = cfg.SessionName
+		if cfg.ExternalID != "" {
+			o.ExternalID = aws.String(cfg.ExternalID)
+		}
+	})
+	return aws.NewCredentialsCache(provider), nil
+}
@@ -23,13 +23,15 @@ import (
 // addMetastoreCommand adds the metastore command to the application
 func addMetastoreCommand(app *kingpin.Application) {
 	var cfg Config
+	var sdConfigPath string
 
 	cmd := app.Command("metastore", "Query metastore for stream information using remote storage bucket")
-	cmd.Flag("bucket", "Remote bucket name").Required().StringVar(&cfg.Bucket)
-	cmd.Flag("org-id", "Organization ID").Required().StringVar(&cfg.OrgID)
+	cmd.Flag("bucket", "Remote bucket name").StringVar(&cfg.Bucket)
+	cmd.Flag("org-id", "Organization ID").StringVar(&cfg.OrgID)
 	cmd.Flag("start", "Start time (RFC3339 format)").Required().StringVar(&cfg.Start)
 	cmd.Flag("end", "End time (RFC3339 format)").Required().StringVar(&cfg.End)
 	cmd.Flag("query", "LogQL query to analyze").Required().StringVar(&cfg.Query)
+	cmd.Flag("sd-config", "Path to a service-discovery config (static_configs, file_sd_configs) to fan the query out across multiple buckets/tenants; a config with consul_sd_configs is rejected").StringVar(&sdConfigPath)
 
 	cmd.Action(func(_ *kingpin.ParseContext) error {
 		orgID = cfg.OrgID
@@ -39,15 +41,59 @@ func addMetastoreCommand(app *kingpin.Application) {
 			return err
 		}
 
-		bucket := MustGCSDataobjBucket(cfg.Bucket)
-
 		params, err := logql.NewLiteralParams(cfg.Query, parsed.StartTime, parsed.EndTime, 0, 0, logproto.BACKWARD, 10, nil, nil)
 		if err != nil {
 			return err
 		}
 
-		return queryMetastore(params, bucket)
+		if sdConfigPath == "" {
+			bucket := MustGCSDataobjBucket(cfg.Bucket)
+			return queryMetastore(params, bucket)
+		}
+
+		sdConfig, err := LoadSDConfig(sdConfigPath)
+		if err != nil {
+			return err
+		}
+		if len(sdConfig.ConsulSDConfigs) > 0 {
+			return fmt.Errorf("sd-config %q contains consul_sd_configs, but this tool does not implement a Consul client; remove them or use static_configs/file_sd_configs", sdConfigPath)
+		}
+		targets, err := sdConfig.Resolve(nil)
+		if err != nil {
+			return err
+		}
+
+		return queryMetastoreFleet(params, targets)
+	})
+}
+
+// queryMetastoreFleet resolves each discovered target to its own bucket and
+// fans the query out across all of them concurrently, printing results
+// grouped by target so a partial fleet scan still surfaces useful data.
+func queryMetastoreFleet(params logql.LiteralParams, targets []Target) error {
+	query := params.QueryString()
+	closeIdx := strings.Index(query, "}")
+	streamMatchers, err := syntax.ParseMatchers(query[:closeIdx+1], true)
+	if err != nil {
+		return err
+	}
+
+	results := QueryMetastoreFleet(context.Background(), targets, func(_ context.Context, t Target) ([]*metastore.DataobjSectionDescriptor, error) {
+		bucket := MustGCSDataobjBucket(t.Bucket)
+		return getSectionsForTarget(bucket, t.OrgID, t.IndexStoragePrefix, params.Start(), params.End(), streamMatchers)
 	})
+
+	for _, r := range results {
+		if r.Err != nil {
+			level.Error(logger).Log("msg", "metastore scan failed for target", "target", r.Target.String(), "err", r.Err)
+			continue
+		}
+		level.Info(logger).Log("msg", "metastore sections found", "target", r.Target.String(), "count", len(r.Sections))
+		for _, section := range r.Sections {
+			level.Info(logger).Log("msg", "metastore section", "target", r.Target.String(), "section", fmt.Sprintf("%+v", section))
+		}
+	}
+	return nil
 }
 
 // queryMetastore queries the metastore for stream sections
@@ -73,6 +119,14 @@ func queryMetastore(params logql.LiteralParams, bucket objstore.Bucket) error {
 // getSections queries the metastore for dataobject sections matching the query selector
 // Currently, it does not pass structured metadata predicates
 func getSections(bucket objstore.Bucket, start, end time.Time, streamMatchers []*labels.Matcher) ([]*metastore.DataobjSectionDescriptor, error) {
+	return getSectionsForTarget(bucket, orgID, indexStoragePrefix, start, end, streamMatchers)
+}
+
+// getSectionsForTarget is the target-parameterized form of getSections. It
+// takes orgID and indexStoragePrefix explicitly, rather than from the
+// package-level flags, so that QueryMetastoreFleet can scan several targets
+// concurrently without racing on shared state.
+func getSectionsForTarget(bucket objstore.Bucket, orgID, indexStoragePrefix string, start, end time.Time, streamMatchers []*labels.Matcher) ([]*metastore.DataobjSectionDescriptor, error) {
 	ctx := user.InjectOrgID(context.Background(), orgID)
 	ms := metastore.NewObjectMetastore(
 		bucket,
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+)
+
+// Target identifies a single bucket to scan for stream sections, taken
+// as-is from a static_configs or file_sd_configs entry (or returned by a
+// Consul resolver passed to Resolve); no relabel rules are applied.
+type Target struct {
+	Bucket             string `yaml:"bucket"`
+	OrgID              string `yaml:"org_id"`
+	IndexStoragePrefix string `yaml:"index_storage_prefix"`
+}
+
+func (t Target) String() string {
+	return fmt.Sprintf("%s/%s", t.Bucket, t.OrgID)
+}
+
+// StaticConfig is a fixed list of targets, mirroring Prometheus' static_configs.
+type StaticConfig struct {
+	Targets []Target `yaml:"targets"`
+}
+
+// ConsulSDConfig holds one consul_sd_configs entry, mirroring Prometheus'
+// consul_sd_configs schema. Nothing in this tool queries Consul: Resolve
+// hands each entry to the caller-supplied resolver, and the metastore
+// command rejects a config that contains any.
+type ConsulSDConfig struct {
+	Server   string   `yaml:"server"`
+	Services []string `yaml:"services"`
+	Tag      string   `yaml:"tag"`
+}
+
+// FileSDConfig discovers targets from one or more YAML/JSON files matching a
+// glob pattern, mirroring Prometheus' file_sd_configs. Files are re-read on
+// every call to Resolve rather than watched, since the metastore tool runs
+// one-shot scans rather than a long-lived discovery loop.
+type FileSDConfig struct {
+	Files []string `yaml:"files"`
+}
+
+// SDConfig is the top-level --sd-config schema.
+type SDConfig struct {
+	StaticConfigs   []StaticConfig   `yaml:"static_configs"`
+	ConsulSDConfigs []ConsulSDConfig `yaml:"consul_sd_configs"`
+	FileSDConfigs   []FileSDConfig   `yaml:"file_sd_configs"`
+}
+
+// LoadSDConfig reads and parses an SDConfig from path.
+func LoadSDConfig(path string) (*SDConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sd-config: %w", err)
+	}
+	var cfg SDConfig
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parse sd-config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Resolve expands the configuration into the flat list of targets to scan.
+// Consul-discovered targets are resolved by the caller-supplied
+// resolveConsul function, since the tool doesn't vendor a Consul client
+// directly; pass nil to skip consul_sd_configs entries.
+func (c *SDConfig) Resolve(resolveConsul func(ConsulSDConfig) ([]Target, error)) ([]Target, error) {
+	var targets []Target
+
+	for _, sc := range c.StaticConfigs {
+		targets = append(targets, sc.Targets...)
+	}
+
+	for _, fc := range c.FileSDConfigs {
+		for _, pattern := range fc.Files {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("glob %q: %w", pattern, err)
+			}
+			for _, m := range matches {
+				b, err := os.ReadFile(m)
+				if err != nil {
+					return nil, fmt.Errorf("read file-sd target file %q: %w", m, err)
+				}
+				var fileTargets []Target
+				if err := yaml.Unmarshal(b, &fileTargets); err != nil {
+					return nil, fmt.Errorf("parse file-sd target file %q: %w", m, err)
+				}
+				targets = append(targets, fileTargets...)
+			}
+		}
+	}
+
+	for _, cc := range c.ConsulSDConfigs {
+		if resolveConsul == nil {
+			continue
+		}
+		resolved, err := resolveConsul(cc)
+		if err != nil {
+			return nil, fmt.Errorf("resolve consul_sd_config %q: %w", cc.Server, err)
+		}
+		targets = append(targets, resolved...)
+	}
+
+	return targets, nil
+}
+
+// TargetResult holds the outcome of scanning a single target, so a partial
+// fleet scan can still return useful data when some targets error out.
+type TargetResult struct {
+	Target   Target
+	Sections []*metastore.DataobjSectionDescriptor
+	Err      error
+}
+
+// maxConcurrentTargetScans bounds the worker pool used by
+// QueryMetastoreFleet, so scanning a large fleet of buckets doesn't open an
+// unbounded number of connections at once.
+const maxConcurrentTargetScans = 16
+
+// QueryMetastoreFleet runs scan concurrently across targets, bounded by
+// maxConcurrentTargetScans, and returns one TargetResult per target grouped
+// by target rather than failing the whole scan on the first error.
+func QueryMetastoreFleet(ctx context.Context, targets []Target, scan func(ctx context.Context, t Target) ([]*metastore.DataobjSectionDescriptor, error)) []TargetResult {
+	results := make([]TargetResult, len(targets))
+	sem := make(chan struct{}, maxConcurrentTargetScans)
+
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, t Target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			sections, err := scan(ctx, t)
+			results[i] = TargetResult{Target: t, Sections: sections, Err: err}
+		}(i, t)
+	}
+	wg.Wait()
+
+	return results
+}
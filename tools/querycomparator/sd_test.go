@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+)
+
+func TestLoadSDConfigParsesAllConfigKinds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sd-config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+static_configs:
+  - targets:
+      - bucket: b1
+        org_id: o1
+consul_sd_configs:
+  - server: consul:8500
+    services: [loki]
+file_sd_configs:
+  - files: ["targets/*.yaml"]
+`), 0o600))
+
+	cfg, err := LoadSDConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, []StaticConfig{{Targets: []Target{{Bucket: "b1", OrgID: "o1"}}}}, cfg.StaticConfigs)
+	require.Equal(t, []ConsulSDConfig{{Server: "consul:8500", Services: []string{"loki"}}}, cfg.ConsulSDConfigs)
+	require.Equal(t, []FileSDConfig{{Files: []string{"targets/*.yaml"}}}, cfg.FileSDConfigs)
+}
+
+func TestSDConfigResolveCombinesStaticAndFileTargets(t *testing.T) {
+	dir := t.TempDir()
+	fileTargetsPath := filepath.Join(dir, "targets.yaml")
+	require.NoError(t, os.WriteFile(fileTargetsPath, []byte(`
+- bucket: b2
+  org_id: o2
+`), 0o600))
+
+	cfg := &SDConfig{
+		StaticConfigs: []StaticConfig{{Targets: []Target{{Bucket: "b1", OrgID: "o1"}}}},
+		FileSDConfigs: []FileSDConfig{{Files: []string{filepath.Join(dir, "*.yaml")}}},
+	}
+
+	targets, err := cfg.Resolve(nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Target{{Bucket: "b1", OrgID: "o1"}, {Bucket: "b2", OrgID: "o2"}}, targets)
+}
+
+func TestSDConfigResolveSkipsConsulConfigsWithoutResolver(t *testing.T) {
+	cfg := &SDConfig{ConsulSDConfigs: []ConsulSDConfig{{Server: "consul:8500"}}}
+
+	targets, err := cfg.Resolve(nil)
+	require.NoError(t, err)
+	require.Empty(t, targets)
+}
+
+func TestSDConfigResolveUsesConsulResolver(t *testing.T) {
+	cfg := &SDConfig{ConsulSDConfigs: []ConsulSDConfig{{Server: "consul:8500"}}}
+
+	targets, err := cfg.Resolve(func(cc ConsulSDConfig) ([]Target, error) {
+		require.Equal(t, "consul:8500", cc.Server)
+		return []Target{{Bucket: "b3", OrgID: "o3"}}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Target{{Bucket: "b3", OrgID: "o3"}}, targets)
+}
+
+func TestSDConfigResolvePropagatesConsulResolverError(t *testing.T) {
+	wantErr := errors.New("consul unreachable")
+	cfg := &SDConfig{ConsulSDConfigs: []ConsulSDConfig{{Server: "consul:8500"}}}
+
+	_, err := cfg.Resolve(func(ConsulSDConfig) ([]Target, error) { return nil, wantErr })
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestQueryMetastoreFleetGroupsResultsByTargetIncludingErrors(t *testing.T) {
+	targets := []Target{{Bucket: "b1"}, {Bucket: "b2"}}
+	wantErr := errors.New("scan failed")
+
+	results := QueryMetastoreFleet(context.Background(), targets, func(_ context.Context, t Target) ([]*metastore.DataobjSectionDescriptor, error) {
+		if t.Bucket == "b2" {
+			return nil, wantErr
+		}
+		return []*metastore.DataobjSectionDescriptor{{}}, nil
+	})
+
+	require.Len(t, results, 2)
+	require.Equal(t, targets[0], results[0].Target)
+	require.Len(t, results[0].Sections, 1)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, targets[1], results[1].Target)
+	require.ErrorIs(t, results[1].Err, wantErr)
+}
@@ -2,52 +2,299 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
+	"sync"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	glog "github.com/go-kit/log"
 	"github.com/grafana/dskit/flagext"
+	"github.com/thanos-io/objstore"
+	objstoreutil "github.com/thanos-io/objstore/client"
+	"gopkg.in/yaml.v2"
+
+	storagebucket "github.com/grafana/loki/v3/pkg/storage/bucket"
+	"github.com/grafana/loki/v3/pkg/storage/bucket/azure"
 	"github.com/grafana/loki/v3/pkg/storage/bucket/gcs"
 	"github.com/grafana/loki/v3/pkg/storage/bucket/s3"
-	"github.com/thanos-io/objstore"
+	"github.com/grafana/loki/v3/pkg/storage/bucket/swift"
+
+	// Registered for side effects: pulling these in adds the "bos" and
+	// "oci" backends to the storagebucket.Provider registry, where
+	// storagebucket.NewBucket can build them. NewDataobjBucket itself
+	// accepts only s3, gcs, azure and swift.
+	_ "github.com/grafana/loki/v3/pkg/storage/bucket/bos"
+	_ "github.com/grafana/loki/v3/pkg/storage/bucket/oci"
 )
 
-// MustS3DataobjBucket creates a S3 bucket client for dataobj storage
-// The access key id, secret access key, and session token are required for S3 dataobj bucket and must be provided.
-// The region endpoint follows the format "s3.<aws region name>.amazonaws.com" e.g. "s3.eu-south-2.amazonaws.com".
-func MustS3DataobjBucket(bucketName string, regionEndpoint string) objstore.Bucket {
-	accessKeyID := ""
-	secretAccessKey := ""
-	sessionToken := ""
+// DataobjBucketConfig names the object storage backend to construct and
+// carries that backend's own provider Config, the same backend-selector
+// shape the storage package's own Config uses for the ingester/querier
+// clients. NewDataobjBucket marshals the Config matching Backend to YAML
+// and dispatches it through the storagebucket.Provider registry.
+type DataobjBucketConfig struct {
+	Backend string
+
+	S3    s3.Config
+	GCS   gcs.Config
+	Azure azure.Config
+	Swift swift.Config
+}
 
-	if accessKeyID == "" || secretAccessKey == "" || sessionToken == "" {
-		log.Fatal("access key id, secret access key, and session token are required for S3 dataobj bucket")
+// NewDataobjBucket builds a dataobj storage bucket client for whichever
+// backend cfg.Backend names, going through the storagebucket.Provider
+// registry so MustS3DataobjBucket, MustGCSDataobjBucket,
+// MustAzureDataobjBucket and MustSwiftDataobjBucket share one construction
+// and prefixing path instead of each repeating it. Any other backend,
+// including the registered bos and oci, returns an error.
+func NewDataobjBucket(cfg DataobjBucketConfig) (objstore.Bucket, error) {
+	var providerCfg interface{}
+	switch cfg.Backend {
+	case "s3":
+		providerCfg = cfg.S3
+	case "gcs":
+		providerCfg = cfg.GCS
+	case "azure":
+		providerCfg = cfg.Azure
+	case "swift":
+		providerCfg = cfg.Swift
+	default:
+		return nil, fmt.Errorf("unsupported dataobj bucket backend: %q", cfg.Backend)
 	}
 
-	bkt, err := s3.NewBucketClient(s3.Config{
-		Endpoint:        regionEndpoint,
-		BucketName:      bucketName,
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: flagext.SecretWithValue(secretAccessKey),
-		SessionToken:    flagext.SecretWithValue(sessionToken),
-	}, "querycomparator", glog.NewNopLogger(), nil)
+	cfgYAML, err := yaml.Marshal(providerCfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+
+	bkt, err := storagebucket.NewBucket(context.Background(), cfg.Backend, cfgYAML, "querycomparator", glog.NewNopLogger(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return objstore.NewPrefixedBucket(bkt, "dataobj"), nil
+}
+
+// MustS3DataobjBucket creates a S3 bucket client for dataobj storage.
+// Credentials are resolved from credProvider rather than hard-coded,
+// so callers running on EKS/IRSA or assuming a role via STS can pass a
+// provider built with NewS3CredentialsProvider instead of baking in a
+// static access key, secret, and session token. cfg's own AccessKeyID,
+// SecretAccessKey and SessionToken fields are ignored; everything else
+// (Endpoint, BucketName, etc.) is used as given.
+//
+// The returned bucket re-resolves credentials from credProvider before
+// every operation -- cheap, since credProvider is expected to be wrapped
+// in an aws.CredentialsCache -- and only rebuilds the underlying S3
+// client when the resolved credentials actually changed, so rotating
+// STS/IRSA credentials take effect without a restart.
+func MustS3DataobjBucket(cfg s3.Config, credProvider aws.CredentialsProvider) objstore.Bucket {
+	return &rotatingS3Bucket{cfg: cfg, credProvider: credProvider}
+}
+
+// rotatingS3Bucket is an objstore.Bucket that re-resolves its underlying S3
+// client's credentials from credProvider on demand, rebuilding the client
+// whenever the resolved access key or session token changes.
+type rotatingS3Bucket struct {
+	cfg          s3.Config
+	credProvider aws.CredentialsProvider
+
+	mu               sync.Mutex
+	current          objstore.Bucket
+	lastAccessKeyID  string
+	lastSessionToken string
+}
+
+func (r *rotatingS3Bucket) bucket(ctx context.Context) (objstore.Bucket, error) {
+	creds, err := r.credProvider.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve S3 credentials: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil && creds.AccessKeyID == r.lastAccessKeyID && creds.SessionToken == r.lastSessionToken {
+		return r.current, nil
+	}
+
+	cfg := r.cfg
+	cfg.AccessKeyID = creds.AccessKeyID
+	cfg.SecretAccessKey = flagext.SecretWithValue(creds.SecretAccessKey)
+	cfg.SessionToken = flagext.SecretWithValue(creds.SessionToken)
+
+	bkt, err := NewDataobjBucket(DataobjBucketConfig{Backend: "s3", S3: cfg})
+	if err != nil {
+		return nil, err
+	}
+	old := r.current
+	r.current = bkt
+	r.lastAccessKeyID = creds.AccessKeyID
+	r.lastSessionToken = creds.SessionToken
+	if old != nil {
+		// The S3 client being replaced owns its own HTTP transport/connection
+		// pool; dropping it without closing would leak those connections on
+		// every credential rotation.
+		_ = old.Close()
+	}
+	return bkt, nil
+}
+
+// Name implements objstore.Bucket.
+func (r *rotatingS3Bucket) Name() string { return r.cfg.BucketName }
+
+// Close implements objstore.Bucket.
+func (r *rotatingS3Bucket) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
+
+// Upload implements objstore.Bucket.
+func (r *rotatingS3Bucket) Upload(ctx context.Context, name string, rd io.Reader, opts ...objstore.ObjectUploadOption) error {
+	bkt, err := r.bucket(ctx)
+	if err != nil {
+		return err
+	}
+	return bkt.Upload(ctx, name, rd, opts...)
+}
+
+// Get implements objstore.Bucket.
+func (r *rotatingS3Bucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	bkt, err := r.bucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bkt.Get(ctx, name)
+}
+
+// GetRange implements objstore.Bucket.
+func (r *rotatingS3Bucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	bkt, err := r.bucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bkt.GetRange(ctx, name, off, length)
+}
+
+// Exists implements objstore.Bucket.
+func (r *rotatingS3Bucket) Exists(ctx context.Context, name string) (bool, error) {
+	bkt, err := r.bucket(ctx)
+	if err != nil {
+		return false, err
+	}
+	return bkt.Exists(ctx, name)
+}
+
+// Attributes implements objstore.Bucket.
+func (r *rotatingS3Bucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	bkt, err := r.bucket(ctx)
+	if err != nil {
+		return objstore.ObjectAttributes{}, err
+	}
+	return bkt.Attributes(ctx, name)
+}
+
+// Delete implements objstore.Bucket.
+func (r *rotatingS3Bucket) Delete(ctx context.Context, name string) error {
+	bkt, err := r.bucket(ctx)
+	if err != nil {
+		return err
 	}
+	return bkt.Delete(ctx, name)
+}
 
-	prefixedBkt := objstore.NewPrefixedBucket(bkt, "dataobj")
-	return prefixedBkt
+// Iter implements objstore.Bucket.
+func (r *rotatingS3Bucket) Iter(ctx context.Context, dir string, f func(string) error, opts ...objstore.IterOption) error {
+	bkt, err := r.bucket(ctx)
+	if err != nil {
+		return err
+	}
+	return bkt.Iter(ctx, dir, f, opts...)
+}
+
+// IsObjNotFoundErr implements objstore.Bucket. The underlying client isn't
+// needed to classify an error, so this doesn't go through bucket(ctx) and
+// can't itself fail credential resolution.
+func (r *rotatingS3Bucket) IsObjNotFoundErr(err error) bool {
+	r.mu.Lock()
+	current := r.current
+	r.mu.Unlock()
+	return current != nil && current.IsObjNotFoundErr(err)
+}
+
+// IsAccessDeniedErr implements objstore.Bucket.
+func (r *rotatingS3Bucket) IsAccessDeniedErr(err error) bool {
+	r.mu.Lock()
+	current := r.current
+	r.mu.Unlock()
+	return current != nil && current.IsAccessDeniedErr(err)
 }
 
 // MustGCSDataobjBucket creates a GCS bucket client for dataobj storage
 func MustGCSDataobjBucket(bucketName string) objstore.Bucket {
-	bkt, err := gcs.NewBucketClient(context.Background(), gcs.Config{
-		BucketName: bucketName,
-	}, "querycomparator", glog.NewNopLogger(), nil)
+	bkt, err := NewDataobjBucket(DataobjBucketConfig{
+		Backend: "gcs",
+		GCS:     gcs.Config{BucketName: bucketName},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return bkt
+}
+
+// MustAzureDataobjBucket creates an Azure Blob bucket client for dataobj
+// storage. account and accountKey are required, since Azure has no
+// ambient-credential fallback analogous to GCS's application default
+// credentials.
+func MustAzureDataobjBucket(bucketName, account, accountKey string) objstore.Bucket {
+	if account == "" || accountKey == "" {
+		log.Fatal("storage account name and key are required for Azure dataobj bucket")
+	}
+
+	bkt, err := NewDataobjBucket(DataobjBucketConfig{
+		Backend: "azure",
+		Azure: azure.Config{
+			StorageAccountName: account,
+			StorageAccountKey:  flagext.SecretWithValue(accountKey),
+			ContainerName:      bucketName,
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return bkt
+}
+
+// MustSwiftDataobjBucket creates an OpenStack Swift bucket client for
+// dataobj storage. Authentication is taken from the standard OS_* Swift
+// environment variables, the same ambient-credential convention
+// MustGCSDataobjBucket uses for GCS.
+func MustSwiftDataobjBucket(containerName string) objstore.Bucket {
+	bkt, err := NewDataobjBucket(DataobjBucketConfig{
+		Backend: "swift",
+		Swift:   swift.Config{ContainerName: containerName},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return bkt
+}
+
+// MustDataobjBucketFromYAML builds a dataobj storage bucket client from a
+// Thanos-style `type: <S3|GCS|AZURE|SWIFT>` / `config: {...}` YAML blob --
+// the same shape objstoreutil.NewBucket accepts -- for callers that already
+// have one, e.g. lifted from a running component's storage config, instead
+// of populating a DataobjBucketConfig by hand.
+func MustDataobjBucketFromYAML(confContentYAML []byte) objstore.Bucket {
+	bkt, err := objstoreutil.NewBucket(glog.NewNopLogger(), confContentYAML, "querycomparator")
 	if err != nil {
 		log.Fatal(err)
 	}
-	objBucket := objstore.NewPrefixedBucket(bkt, "dataobj")
-	return objBucket
+	return objstore.NewPrefixedBucket(bkt, "dataobj")
 }
 
 // MustRawGCSBucket creates a GCS bucket client for raw storage
@@ -0,0 +1,65 @@
+package jsonpath
+
+import "go.yaml.in/yaml/v4"
+
+// PseudoType classifies the kind of selector that produced a match, so
+// downstream consumers can group or filter results without re-parsing the
+// path string.
+type PseudoType string
+
+const (
+	PseudoTypeObject       PseudoType = "object"
+	PseudoTypeArrayElement PseudoType = "array-element"
+	PseudoTypeRecursive    PseudoType = "recursive"
+	PseudoTypeFilter       PseudoType = "filter"
+	PseudoTypeWildcard     PseudoType = "wildcard"
+	PseudoTypeSlice        PseudoType = "slice"
+)
+
+// MatchMeta annotates a single match returned by QueryWithAnnotations with
+// enough structure for post-processing tools — collapsing matches by
+// parent, extracting nesting, or grouping by selector kind — without
+// re-parsing Path.
+type MatchMeta struct {
+	Path         string
+	ParentPath   string
+	PropertyName string
+	Index        int
+	PseudoType   PseudoType
+}
+
+// AnnotatedPredicate reports whether node (reached via ctx) is a match, and
+// if so which selector kind produced it. It is the annotated counterpart of
+// nodePredicate, used by QueryWithAnnotations.
+type AnnotatedPredicate func(ctx FilterContext, node *yaml.Node) (matched bool, pseudoType PseudoType)
+
+// QueryWithAnnotations is an opt-in alternative to NewStreamIterator that,
+// alongside each matched node, records a MatchMeta describing the selector
+// that produced it. Callers that only need the matched nodes themselves
+// should keep using NewStreamIterator directly, since tracking annotations
+// costs an allocation per match.
+func QueryWithAnnotations(root *yaml.Node, pred AnnotatedPredicate) ([]*yaml.Node, []MatchMeta, error) {
+	ctx := NewFilterContext(root)
+	fc := ctx.(*filterContext)
+	fc.EnablePathTracking()
+	fc.EnablePropertyTracking()
+	fc.EnableIndexTracking()
+
+	it := NewStreamIterator(root, ctx, func(c FilterContext, n *yaml.Node) bool {
+		matched, pseudoType := pred(c, n)
+		if !matched {
+			return false
+		}
+		ctx.RecordAnnotation(pseudoType)
+		return true
+	})
+
+	var nodes []*yaml.Node
+	for it.Next() {
+		nodes = append(nodes, it.Node())
+	}
+	if err := it.Err(); err != nil {
+		return nil, nil, err
+	}
+	return nodes, ctx.Annotations(), nil
+}
@@ -0,0 +1,135 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"go.yaml.in/yaml/v4"
+)
+
+func TestEscapePathSegment(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"with'quote", `with\'quote`},
+		{`with\backslash`, `with\\backslash`},
+		{"", ""},
+	}
+
+	for _, c := range cases {
+		if got := escapePathSegment(c.in); got != c.want {
+			t.Errorf("escapePathSegment(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestNormalizePathSegment(t *testing.T) {
+	if got, want := normalizePathSegment("key"), "['key']"; got != want {
+		t.Errorf("normalizePathSegment(%q) = %q, want %q", "key", got, want)
+	}
+	if got, want := normalizePathSegment("it's"), `['it\'s']`; got != want {
+		t.Errorf("normalizePathSegment(%q) = %q, want %q", "it's", got, want)
+	}
+}
+
+func TestNormalizeIndexSegment(t *testing.T) {
+	if got, want := normalizeIndexSegment(0), "[0]"; got != want {
+		t.Errorf("normalizeIndexSegment(0) = %q, want %q", got, want)
+	}
+	if got, want := normalizeIndexSegment(42), "[42]"; got != want {
+		t.Errorf("normalizeIndexSegment(42) = %q, want %q", got, want)
+	}
+}
+
+func TestFilterContextPathTrackingDisabledByDefault(t *testing.T) {
+	root := &yaml.Node{Kind: yaml.DocumentNode}
+	ctx := NewFilterContext(root)
+
+	if got, want := ctx.Path(), "$"; got != want {
+		t.Fatalf("Path() = %q, want %q", got, want)
+	}
+	ctx.PushPathSegment(normalizePathSegment("a"))
+	if got, want := ctx.Path(), "$"; got != want {
+		t.Fatalf("Path() with tracking disabled = %q, want %q", got, want)
+	}
+}
+
+func TestFilterContextPushPopPathSegment(t *testing.T) {
+	root := &yaml.Node{Kind: yaml.DocumentNode}
+	ctx := NewFilterContext(root)
+	fc := ctx.(*filterContext)
+	fc.EnablePathTracking()
+
+	ctx.PushPathSegment(normalizePathSegment("a"))
+	ctx.PushPathSegment(normalizeIndexSegment(0))
+	if got, want := ctx.Path(), "$['a'][0]"; got != want {
+		t.Fatalf("Path() = %q, want %q", got, want)
+	}
+
+	ctx.PopPathSegment()
+	if got, want := ctx.Path(), "$['a']"; got != want {
+		t.Fatalf("Path() after pop = %q, want %q", got, want)
+	}
+}
+
+func TestFilterContextRecordAnnotation(t *testing.T) {
+	root := &yaml.Node{Kind: yaml.DocumentNode}
+	ctx := NewFilterContext(root)
+	fc := ctx.(*filterContext)
+	fc.EnablePathTracking()
+	fc.EnablePropertyTracking()
+	fc.EnableIndexTracking()
+
+	ctx.PushPathSegment(normalizePathSegment("store"))
+	ctx.SetPropertyName("store")
+	ctx.SetIndex(-1)
+	ctx.RecordAnnotation(PseudoTypeObject)
+
+	annotations := ctx.Annotations()
+	if len(annotations) != 1 {
+		t.Fatalf("got %d annotations, want 1", len(annotations))
+	}
+	got := annotations[0]
+	if got.Path != "$['store']" {
+		t.Errorf("Path = %q, want %q", got.Path, "$['store']")
+	}
+	if got.ParentPath != "$" {
+		t.Errorf("ParentPath = %q, want %q", got.ParentPath, "$")
+	}
+	if got.PropertyName != "store" {
+		t.Errorf("PropertyName = %q, want %q", got.PropertyName, "store")
+	}
+	if got.PseudoType != PseudoTypeObject {
+		t.Errorf("PseudoType = %q, want %q", got.PseudoType, PseudoTypeObject)
+	}
+}
+
+func TestQueryWithAnnotationsTagsEachMatch(t *testing.T) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(`
+store:
+  book:
+    - title: one
+    - title: two
+`), &root); err != nil {
+		t.Fatalf("decode yaml: %v", err)
+	}
+
+	nodes, metas, err := QueryWithAnnotations(&root, func(_ FilterContext, n *yaml.Node) (bool, PseudoType) {
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+			return true, PseudoTypeObject
+		}
+		return false, ""
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes) != len(metas) {
+		t.Fatalf("got %d nodes but %d metas", len(nodes), len(metas))
+	}
+	for _, m := range metas {
+		if m.PseudoType != PseudoTypeObject {
+			t.Errorf("PseudoType = %q, want %q", m.PseudoType, PseudoTypeObject)
+		}
+	}
+}
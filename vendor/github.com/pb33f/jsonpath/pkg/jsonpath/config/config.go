@@ -28,16 +28,76 @@ func WithStrictRFC9535() Option {
 	}
 }
 
+// ParamKind describes the RFC 9535 parameter/result type of a function
+// extension argument or return value.
+type ParamKind int
+
+const (
+	ParamKindValue ParamKind = iota
+	ParamKindLogical
+	ParamKindNodes
+)
+
+// FunctionExtension describes a custom RFC 9535 function extension, such as
+// `regex_match(...)`, `to_number(...)`, or a domain-specific predicate like
+// `bytes(...)` for parsing Loki log lines.
+type FunctionExtension struct {
+	// ParamKinds lists the expected kind of each argument, in order. The
+	// evaluator validates call sites against this arity/kind signature at
+	// parse time so misuse fails at query compile rather than at row-time.
+	ParamKinds []ParamKind
+	// ReturnKind is the kind of value the function produces.
+	ReturnKind ParamKind
+	// Func is invoked with one evaluated argument per entry in ParamKinds,
+	// in order, and must return a value of ReturnKind.
+	Func func(args []any) (any, error)
+}
+
 type Config interface {
 	PropertyNameEnabled() bool
 	JSONPathPlusEnabled() bool
 	LazyContextTrackingEnabled() bool
+
+	// FunctionExtensions returns the set of registered function extensions,
+	// keyed by name.
+	FunctionExtensions() map[string]FunctionExtension
+	// BuiltinDisabled reports whether the builtin function of the given name
+	// has been disabled via WithoutBuiltin, e.g. for security-hardened
+	// deployments that want to restrict the query surface.
+	BuiltinDisabled(name string) bool
 }
 
 type config struct {
 	propertyNameExtension bool
 	strictRFC9535         bool
 	lazyContextTracking   bool
+
+	functionExtensions map[string]FunctionExtension
+	disabledBuiltins   map[string]struct{}
+}
+
+// WithFunctionExtension registers a custom RFC 9535 function extension under
+// name, so callers can add functions like regex_match, to_number, or
+// domain-specific predicates without forking the evaluator.
+func WithFunctionExtension(name string, fn FunctionExtension) Option {
+	return func(cfg *config) {
+		if cfg.functionExtensions == nil {
+			cfg.functionExtensions = make(map[string]FunctionExtension)
+		}
+		cfg.functionExtensions[name] = fn
+	}
+}
+
+// WithoutBuiltin disables the builtin function of the given name, e.g. for
+// operators that want to restrict the query surface in security-hardened
+// deployments.
+func WithoutBuiltin(name string) Option {
+	return func(cfg *config) {
+		if cfg.disabledBuiltins == nil {
+			cfg.disabledBuiltins = make(map[string]struct{})
+		}
+		cfg.disabledBuiltins[name] = struct{}{}
+	}
 }
 
 func (c *config) PropertyNameEnabled() bool {
@@ -57,6 +117,19 @@ func (c *config) LazyContextTrackingEnabled() bool {
 	return c.lazyContextTracking
 }
 
+// FunctionExtensions returns the set of registered function extensions,
+// keyed by name.
+func (c *config) FunctionExtensions() map[string]FunctionExtension {
+	return c.functionExtensions
+}
+
+// BuiltinDisabled reports whether the builtin function of the given name has
+// been disabled via WithoutBuiltin.
+func (c *config) BuiltinDisabled(name string) bool {
+	_, ok := c.disabledBuiltins[name]
+	return ok
+}
+
 func New(opts ...Option) Config {
 	cfg := &config{}
 	for _, opt := range opts {
@@ -0,0 +1,90 @@
+package config
+
+import "testing"
+
+func TestWithFunctionExtensionRegistersExtension(t *testing.T) {
+	called := false
+	fn := FunctionExtension{
+		ParamKinds: []ParamKind{ParamKindValue},
+		ReturnKind: ParamKindValue,
+		Func: func(args []any) (any, error) {
+			called = true
+			return args[0], nil
+		},
+	}
+
+	cfg := New(WithFunctionExtension("double", fn))
+
+	got, ok := cfg.FunctionExtensions()["double"]
+	if !ok {
+		t.Fatalf("expected %q to be registered", "double")
+	}
+	if _, err := got.Func([]any{1}); err != nil || !called {
+		t.Fatalf("registered Func was not preserved: called=%v err=%v", called, err)
+	}
+}
+
+func TestWithFunctionExtensionMultipleNames(t *testing.T) {
+	cfg := New(
+		WithFunctionExtension("a", FunctionExtension{}),
+		WithFunctionExtension("b", FunctionExtension{}),
+	)
+
+	exts := cfg.FunctionExtensions()
+	if len(exts) != 2 {
+		t.Fatalf("expected 2 extensions, got %d", len(exts))
+	}
+	if _, ok := exts["a"]; !ok {
+		t.Fatalf("expected %q to be registered", "a")
+	}
+	if _, ok := exts["b"]; !ok {
+		t.Fatalf("expected %q to be registered", "b")
+	}
+}
+
+func TestWithoutBuiltinDisablesBuiltin(t *testing.T) {
+	cfg := New(WithoutBuiltin("length"))
+
+	if !cfg.BuiltinDisabled("length") {
+		t.Fatal("expected \"length\" to be disabled")
+	}
+	if cfg.BuiltinDisabled("count") {
+		t.Fatal("expected \"count\" to remain enabled")
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg := New()
+
+	if cfg.PropertyNameEnabled() {
+		t.Fatal("expected property name extension to be disabled by default")
+	}
+	if !cfg.JSONPathPlusEnabled() {
+		t.Fatal("expected JSONPath Plus extensions to be enabled by default")
+	}
+	if cfg.LazyContextTrackingEnabled() {
+		t.Fatal("expected lazy context tracking to be disabled by default")
+	}
+	if cfg.FunctionExtensions() != nil {
+		t.Fatal("expected no function extensions by default")
+	}
+}
+
+func TestWithStrictRFC9535DisablesJSONPathPlus(t *testing.T) {
+	cfg := New(WithStrictRFC9535())
+
+	if cfg.JSONPathPlusEnabled() {
+		t.Fatal("expected JSONPath Plus extensions to be disabled under strict RFC 9535")
+	}
+}
+
+func TestWithPropertyNameExtensionAndLazyContextTracking(t *testing.T) {
+	cfg := New(WithPropertyNameExtension(), WithLazyContextTracking())
+
+	if !cfg.PropertyNameEnabled() {
+		t.Fatal("expected property name extension to be enabled")
+	}
+	if !cfg.LazyContextTrackingEnabled() {
+		t.Fatal("expected lazy context tracking to be enabled")
+	}
+}
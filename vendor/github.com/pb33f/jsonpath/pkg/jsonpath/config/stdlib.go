@@ -0,0 +1,107 @@
+package config
+
+import "regexp"
+
+// StandardLibrary is the set of RFC 9535 built-in functions, expressed as
+// FunctionExtensions so they share validation and dispatch with any
+// user-registered extension. Evaluators that don't special-case the
+// well-known names can instead seed their function table from this map.
+var StandardLibrary = map[string]FunctionExtension{
+	"length": {
+		ParamKinds: []ParamKind{ParamKindValue},
+		ReturnKind: ParamKindValue,
+		Func:       lengthFunc,
+	},
+	"count": {
+		ParamKinds: []ParamKind{ParamKindNodes},
+		ReturnKind: ParamKindValue,
+		Func:       countFunc,
+	},
+	"match": {
+		ParamKinds: []ParamKind{ParamKindValue, ParamKindValue},
+		ReturnKind: ParamKindLogical,
+		Func:       matchFunc,
+	},
+	"search": {
+		ParamKinds: []ParamKind{ParamKindValue, ParamKindValue},
+		ReturnKind: ParamKindLogical,
+		Func:       searchFunc,
+	},
+	"value": {
+		ParamKinds: []ParamKind{ParamKindNodes},
+		ReturnKind: ParamKindValue,
+		Func:       valueFunc,
+	},
+}
+
+// lengthFunc implements the `length` function: the length of a string,
+// array, or object, or Nothing for any other type.
+func lengthFunc(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case string:
+		return len([]rune(v)), nil
+	case []any:
+		return len(v), nil
+	case map[string]any:
+		return len(v), nil
+	default:
+		return nil, nil
+	}
+}
+
+// countFunc implements the `count` function: the number of nodes in a
+// nodelist.
+func countFunc(args []any) (any, error) {
+	nodes, ok := args[0].([]any)
+	if !ok {
+		return 0, nil
+	}
+	return len(nodes), nil
+}
+
+// matchFunc implements the `match` function: true if the full input string
+// matches the I-Regexp pattern.
+func matchFunc(args []any) (any, error) {
+	input, pattern, ok := stringPair(args)
+	if !ok {
+		return false, nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return false, nil
+	}
+	return re.MatchString(input), nil
+}
+
+// searchFunc implements the `search` function: true if any substring of the
+// input string matches the I-Regexp pattern.
+func searchFunc(args []any) (any, error) {
+	input, pattern, ok := stringPair(args)
+	if !ok {
+		return false, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, nil
+	}
+	return re.MatchString(input), nil
+}
+
+func stringPair(args []any) (string, string, bool) {
+	if len(args) != 2 {
+		return "", "", false
+	}
+	a, aok := args[0].(string)
+	b, bok := args[1].(string)
+	return a, b, aok && bok
+}
+
+// valueFunc implements the `value` function: the value of the node if the
+// nodelist contains exactly one node, or Nothing otherwise.
+func valueFunc(args []any) (any, error) {
+	nodes, ok := args[0].([]any)
+	if !ok || len(nodes) != 1 {
+		return nil, nil
+	}
+	return nodes[0], nil
+}
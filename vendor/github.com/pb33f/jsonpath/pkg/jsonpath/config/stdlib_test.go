@@ -0,0 +1,129 @@
+package config
+
+import "testing"
+
+func TestLengthFunc(t *testing.T) {
+	cases := []struct {
+		name string
+		arg  any
+		want any
+	}{
+		{"string", "hello", 5},
+		{"unicode string counts runes", "héllo", 5},
+		{"array", []any{1, 2, 3}, 3},
+		{"object", map[string]any{"a": 1, "b": 2}, 2},
+		{"unsupported type returns Nothing", 42, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := lengthFunc([]any{c.arg})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Fatalf("lengthFunc(%v) = %v, want %v", c.arg, got, c.want)
+			}
+		})
+	}
+}
+
+func TestCountFunc(t *testing.T) {
+	got, err := countFunc([]any{[]any{1, 2, 3}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 3 {
+		t.Fatalf("countFunc() = %v, want 3", got)
+	}
+
+	got, err = countFunc([]any{"not a nodelist"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 0 {
+		t.Fatalf("countFunc() with non-nodelist = %v, want 0", got)
+	}
+}
+
+func TestMatchFunc(t *testing.T) {
+	cases := []struct {
+		input, pattern string
+		want           bool
+	}{
+		{"abc", "abc", true},
+		{"abc", "ab", false}, // match requires the FULL input to match
+		{"abc", "a.c", true},
+		{"abc", "[", false}, // invalid pattern is a non-match, not an error
+	}
+
+	for _, c := range cases {
+		got, err := matchFunc([]any{c.input, c.pattern})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != c.want {
+			t.Fatalf("matchFunc(%q, %q) = %v, want %v", c.input, c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestSearchFunc(t *testing.T) {
+	cases := []struct {
+		input, pattern string
+		want           bool
+	}{
+		{"hello world", "wor", true},
+		{"hello world", "^world", false},
+		{"hello world", "", true},
+		{"hello world", "[", false}, // invalid pattern is a non-match, not an error
+	}
+
+	for _, c := range cases {
+		got, err := searchFunc([]any{c.input, c.pattern})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != c.want {
+			t.Fatalf("searchFunc(%q, %q) = %v, want %v", c.input, c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestValueFunc(t *testing.T) {
+	got, err := valueFunc([]any{[]any{"only"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "only" {
+		t.Fatalf("valueFunc() = %v, want %q", got, "only")
+	}
+
+	got, err = valueFunc([]any{[]any{"a", "b"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("valueFunc() with multiple nodes = %v, want nil", got)
+	}
+
+	got, err = valueFunc([]any{[]any{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("valueFunc() with no nodes = %v, want nil", got)
+	}
+}
+
+func TestStandardLibraryRegistersAllFiveFunctions(t *testing.T) {
+	want := []string{"length", "count", "match", "search", "value"}
+	for _, name := range want {
+		if _, ok := StandardLibrary[name]; !ok {
+			t.Fatalf("expected StandardLibrary to register %q", name)
+		}
+	}
+	if len(StandardLibrary) != len(want) {
+		t.Fatalf("StandardLibrary has %d entries, want %d", len(StandardLibrary), len(want))
+	}
+}
@@ -21,9 +21,21 @@ type FilterContext interface {
 	SetParentPropertyName(name string)
 
 	Path() string
-	PushPathSegment(segment string)
+	// PushPathSegment adds a path segment. pseudoType is optional and, when
+	// given, tags the segment with the kind of selector that produced it;
+	// RecordAnnotation uses the tag on the most recently pushed segment to
+	// fill in MatchMeta.PseudoType.
+	PushPathSegment(segment string, pseudoType ...PseudoType)
 	PopPathSegment()
 
+	// RecordAnnotation captures a MatchMeta for the current node's position
+	// (Path, PropertyName, Index) and appends it to Annotations(), tagging
+	// it with pseudoType.
+	RecordAnnotation(pseudoType PseudoType)
+	// Annotations returns every MatchMeta recorded so far via
+	// RecordAnnotation, in match order.
+	Annotations() []MatchMeta
+
 	// SetPendingPathSegment stores a path segment for a node (used by wildcards/slices)
 	SetPendingPathSegment(node *yaml.Node, segment string)
 	// GetAndClearPendingPathSegment retrieves and removes a pending path segment for a node
@@ -56,6 +68,8 @@ type filterContext struct {
 	parent                *yaml.Node
 	parentPropertyName    string
 	pathSegments          []string
+	segmentPseudoTypes    []PseudoType // parallel to pathSegments; "" if untagged
+	annotations           []MatchMeta
 	pendingPathSegments   map[*yaml.Node]string // tracks path segments for nodes from wildcards/slices
 	pendingPropertyNames  map[*yaml.Node]string // tracks property names for nodes from wildcards (for @parentProperty)
 	root                  *yaml.Node
@@ -140,12 +154,19 @@ func (fc *filterContext) Path() string {
 	return "$" + strings.Join(fc.pathSegments, "")
 }
 
-// PushPathSegment adds a path segment (should be in normalized form like "['key']" or "[0]")
-func (fc *filterContext) PushPathSegment(segment string) {
+// PushPathSegment adds a path segment (should be in normalized form like
+// "['key']" or "[0]"), optionally tagged with the PseudoType of the
+// selector that produced it.
+func (fc *filterContext) PushPathSegment(segment string, pseudoType ...PseudoType) {
 	if !fc.pathTrackingActive {
 		return
 	}
+	var pt PseudoType
+	if len(pseudoType) > 0 {
+		pt = pseudoType[0]
+	}
 	fc.pathSegments = append(fc.pathSegments, segment)
+	fc.segmentPseudoTypes = append(fc.segmentPseudoTypes, pt)
 }
 
 // PopPathSegment removes the last path segment
@@ -156,6 +177,36 @@ func (fc *filterContext) PopPathSegment() {
 	if len(fc.pathSegments) > 0 {
 		fc.pathSegments = fc.pathSegments[:len(fc.pathSegments)-1]
 	}
+	if len(fc.segmentPseudoTypes) > 0 {
+		fc.segmentPseudoTypes = fc.segmentPseudoTypes[:len(fc.segmentPseudoTypes)-1]
+	}
+}
+
+// RecordAnnotation captures the context's current position as a MatchMeta
+// and appends it to Annotations(). ParentPath is the path with its last
+// segment removed, mirroring how Path() is built from pathSegments.
+func (fc *filterContext) RecordAnnotation(pseudoType PseudoType) {
+	path := fc.Path()
+	parentPath := "$"
+	if len(fc.pathSegments) > 1 {
+		parentPath = "$" + strings.Join(fc.pathSegments[:len(fc.pathSegments)-1], "")
+	}
+	if pseudoType == "" && len(fc.segmentPseudoTypes) > 0 {
+		pseudoType = fc.segmentPseudoTypes[len(fc.segmentPseudoTypes)-1]
+	}
+	fc.annotations = append(fc.annotations, MatchMeta{
+		Path:         path,
+		ParentPath:   parentPath,
+		PropertyName: fc.propertyName,
+		Index:        fc.arrayIndex,
+		PseudoType:   pseudoType,
+	})
+}
+
+// Annotations returns every MatchMeta recorded so far via RecordAnnotation,
+// in match order.
+func (fc *filterContext) Annotations() []MatchMeta {
+	return fc.annotations
 }
 
 // SetPendingPathSegment stores a path segment for a node (used by wildcards/slices)
@@ -315,14 +366,20 @@ func (fc *filterContext) getParentNode(child *yaml.Node) *yaml.Node {
 func (fc *filterContext) Clone() FilterContext {
 	pathCopy := make([]string, len(fc.pathSegments))
 	copy(pathCopy, fc.pathSegments)
+	pseudoTypeCopy := make([]PseudoType, len(fc.segmentPseudoTypes))
+	copy(pseudoTypeCopy, fc.segmentPseudoTypes)
 
-	// Share the pending maps - they're cleared on use anyway
+	// Share the pending maps - they're cleared on use anyway. Annotations
+	// are intentionally NOT copied: each clone records its own matches, and
+	// the caller (e.g. a nested evaluation) reads the clone's Annotations()
+	// independently of the parent's.
 	return &filterContext{
 		_index:                 fc._index,
 		propertyName:           fc.propertyName,
 		parent:                 fc.parent,
 		parentPropertyName:     fc.parentPropertyName,
 		pathSegments:           pathCopy,
+		segmentPseudoTypes:     pseudoTypeCopy,
 		pendingPathSegments:    fc.pendingPathSegments,
 		pendingPropertyNames:   fc.pendingPropertyNames,
 		root:                   fc.root,
@@ -0,0 +1,116 @@
+package jsonpath
+
+import "go.yaml.in/yaml/v4"
+
+// MatchIterator yields matches one at a time instead of materializing the
+// full result set, so a query against a very large YAML document doesn't
+// have to hold every match in memory at once.
+type MatchIterator interface {
+	// Next advances the iterator and reports whether another match is
+	// available.
+	Next() bool
+	// Node returns the node at the current iterator position. It is only
+	// valid after a call to Next that returned true.
+	Node() *yaml.Node
+	// Path returns the normalized JSONPath of the current node, if path
+	// tracking is enabled on the context the iterator was created with.
+	Path() string
+	// Err returns the first error encountered while iterating, if any.
+	Err() error
+}
+
+// nodePredicate reports whether node (reached via ctx, whose PropertyName,
+// Parent, Path, etc. describe node's position) should be yielded as a match.
+type nodePredicate func(ctx FilterContext, node *yaml.Node) bool
+
+// streamIterator performs a streaming depth-first walk of a YAML document,
+// visiting one candidate node per Next() call rather than collecting the
+// whole match set upfront. It shares FilterContext with the non-streaming
+// evaluator so @property/@path/@parentProperty/@index behave identically in
+// both modes.
+type streamIterator struct {
+	pred nodePredicate
+	ctx  FilterContext
+
+	stack []streamFrame
+	cur   *yaml.Node
+	err   error
+}
+
+type streamFrame struct {
+	node           *yaml.Node
+	propertyName   string
+	parentProperty string
+	childIndex     int
+}
+
+// NewStreamIterator returns a MatchIterator that walks root in document
+// order, yielding each node for which pred returns true.
+func NewStreamIterator(root *yaml.Node, ctx FilterContext, pred nodePredicate) MatchIterator {
+	return &streamIterator{
+		pred:  pred,
+		ctx:   ctx,
+		stack: []streamFrame{{node: root}},
+	}
+}
+
+// Next advances the walk to the next matching node, descending into
+// mappings and sequences as it goes. It only keeps the current path stack in
+// memory, so peak memory is bounded by document depth rather than document
+// size.
+func (it *streamIterator) Next() bool {
+	for len(it.stack) > 0 {
+		n := len(it.stack) - 1
+		frame := it.stack[n]
+		it.stack = it.stack[:n]
+
+		it.ctx.SetPropertyName(frame.propertyName)
+		it.ctx.SetParentPropertyName(frame.parentProperty)
+
+		it.pushChildren(frame.node)
+
+		if it.pred(it.ctx, frame.node) {
+			it.cur = frame.node
+			return true
+		}
+	}
+	return false
+}
+
+// pushChildren queues n's children for later visitation, deepest-last so
+// that Next() continues to walk the document in order.
+func (it *streamIterator) pushChildren(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := len(n.Content) - 2; i >= 0; i -= 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			it.stack = append(it.stack, streamFrame{
+				node:           val,
+				propertyName:   key.Value,
+				parentProperty: key.Value,
+			})
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for i := len(n.Content) - 1; i >= 0; i-- {
+			it.stack = append(it.stack, streamFrame{
+				node:       n.Content[i],
+				childIndex: i,
+			})
+		}
+	}
+}
+
+// Node returns the node at the current iterator position.
+func (it *streamIterator) Node() *yaml.Node {
+	return it.cur
+}
+
+// Path returns the normalized JSONPath of the current node.
+func (it *streamIterator) Path() string {
+	return it.ctx.Path()
+}
+
+// Err returns the first error encountered while iterating.
+func (it *streamIterator) Err() error {
+	return it.err
+}
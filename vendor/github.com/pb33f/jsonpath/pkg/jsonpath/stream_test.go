@@ -0,0 +1,138 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"go.yaml.in/yaml/v4"
+)
+
+func mustDecodeYAML(t *testing.T, doc string) *yaml.Node {
+	t.Helper()
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &root); err != nil {
+		t.Fatalf("decode yaml: %v", err)
+	}
+	return &root
+}
+
+func scalarValues(nodes []*yaml.Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Value
+	}
+	return out
+}
+
+func collect(it MatchIterator) ([]*yaml.Node, error) {
+	var nodes []*yaml.Node
+	for it.Next() {
+		nodes = append(nodes, it.Node())
+	}
+	return nodes, it.Err()
+}
+
+func TestStreamIteratorYieldsMatchingScalarsInDocumentOrder(t *testing.T) {
+	root := mustDecodeYAML(t, `
+a: 1
+b: 2
+c: 3
+`)
+
+	ctx := NewFilterContext(root)
+	it := NewStreamIterator(root, ctx, func(_ FilterContext, n *yaml.Node) bool {
+		return n.Kind == yaml.ScalarNode && n.Tag == "!!int"
+	})
+
+	nodes, err := collect(it)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := scalarValues(nodes)
+	want := []string{"1", "2", "3"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestStreamIteratorTracksPropertyName(t *testing.T) {
+	root := mustDecodeYAML(t, `
+name: loki
+version: 3
+`)
+
+	ctx := NewFilterContext(root)
+	fc := ctx.(*filterContext)
+	fc.EnablePropertyTracking()
+
+	var seenNames []string
+	it := NewStreamIterator(root, ctx, func(c FilterContext, n *yaml.Node) bool {
+		if n.Kind != yaml.ScalarNode {
+			return false
+		}
+		seenNames = append(seenNames, c.PropertyName())
+		return true
+	})
+
+	if _, err := collect(it); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"name", "version"}
+	if len(seenNames) != len(want) {
+		t.Fatalf("seenNames = %v, want %v", seenNames, want)
+	}
+	for i := range want {
+		if seenNames[i] != want[i] {
+			t.Fatalf("seenNames = %v, want %v", seenNames, want)
+		}
+	}
+}
+
+func TestStreamIteratorWalksSequences(t *testing.T) {
+	root := mustDecodeYAML(t, `
+- a
+- b
+- c
+`)
+
+	ctx := NewFilterContext(root)
+	it := NewStreamIterator(root, ctx, func(_ FilterContext, n *yaml.Node) bool {
+		return n.Kind == yaml.ScalarNode
+	})
+
+	nodes, err := collect(it)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := scalarValues(nodes)
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestStreamIteratorNoMatchesReturnsFalseImmediately(t *testing.T) {
+	root := mustDecodeYAML(t, `a: 1`)
+
+	ctx := NewFilterContext(root)
+	it := NewStreamIterator(root, ctx, func(_ FilterContext, _ *yaml.Node) bool {
+		return false
+	})
+
+	if it.Next() {
+		t.Fatal("expected no matches")
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}